@@ -0,0 +1,132 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// batchDirective marks an object field that is loaded for a batch of objects
+// at once, instead of once per object
+const batchDirective = "batch"
+
+// BatchField is a batched field together with the object it's a field of
+type BatchField struct {
+	Parent *ast.ObjectDefinition
+	Field  *ast.FieldDefinition
+}
+
+// batchConfig checks the fields listed in the batch section of the project
+// config and returns them as a set of Type.field names
+func batchConfig(definitions []ast.Node, entries []string) (map[string]bool, error) {
+	fields := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		parts := strings.Split(entry, ".")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("'%s' is not a field, fields are listed as Type.field", entry)
+		}
+
+		object, ok := NodeByName(definitions, parts[0]).(*ast.ObjectDefinition)
+		if ok == false {
+			return nil, fmt.Errorf("'%s' is not an object type", parts[0])
+		}
+		if definitionField(object, parts[1]) == nil {
+			return nil, fmt.Errorf("%s has no field '%s'", parts[0], parts[1])
+		}
+
+		fields[entry] = true
+	}
+	return fields, nil
+}
+
+// isBatched checks if the field of an object is batched, either by the @batch
+// directive or by the project config. Fields of interfaces are never batched
+func (gen *Generator) isBatched(parent ast.Node, field *ast.FieldDefinition) bool {
+	object, ok := parent.(*ast.ObjectDefinition)
+	if ok == false {
+		return false
+	}
+	return fieldDirective(field, batchDirective) != nil ||
+		gen.batch[object.Name.Value+"."+field.Name.Value] == true
+}
+
+// batchFields returns the batched fields of an object
+func (gen *Generator) batchFields(parent ast.Node) []*ast.FieldDefinition {
+	var fields []*ast.FieldDefinition
+	if object, ok := parent.(*ast.ObjectDefinition); ok == true {
+		for _, field := range object.Fields {
+			if gen.isBatched(object, field) {
+				fields = append(fields, field)
+			}
+		}
+	}
+	return fields
+}
+
+func batchField(parent *ast.ObjectDefinition, field *ast.FieldDefinition) BatchField {
+	return BatchField{Parent: parent, Field: field}
+}
+
+// validateBatches checks that the batched fields are nullable fields of
+// objects that are resolved by an adapter of the object itself, i.e. they are
+// not fields of a root type, inherited from an interface or resolved by the
+// generated code
+func (v *schemaValidator) validateBatches(definitions []ast.Node) {
+	operations := v.gen.LangConf.rootOperations(definitions)
+	roots := make(map[string]string)
+	for _, operation := range operationNames {
+		if root := operations[operation]; roots[root] == "" {
+			roots[root] = operation
+		}
+	}
+	connections := connectionNodes(definitions)
+
+	for _, def := range definitions {
+		switch def := def.(type) {
+		case *ast.InterfaceDefinition:
+			for _, field := range def.Fields {
+				if directive := fieldDirective(field, batchDirective); directive != nil {
+					v.report(directive.Loc, "%s.%s can't be batched, only fields of object types can",
+						def.Name.Value, field.Name.Value)
+				}
+			}
+		case *ast.ObjectDefinition:
+			for _, field := range def.Fields {
+				if v.gen.isBatched(def, field) == false {
+					continue
+				}
+
+				name := def.Name.Value + "." + field.Name.Value
+				if operation := roots[def.Name.Value]; operation != "" {
+					v.report(field.Name.Loc, "%s can't be batched, %s is the %s root type",
+						name, def.Name.Value, operation)
+				} else if _, ok := connections[def.Name.Value]; ok == true {
+					v.report(field.Name.Loc, "%s can't be batched, %s is part of a connection",
+						name, def.Name.Value)
+				} else if iface := v.declaringInterface(def, field.Name.Value); iface != "" {
+					v.report(field.Name.Loc, "%s can't be batched, it's declared by the interface %s",
+						name, iface)
+				} else if isRelayInterface(def.Interfaces) && field.Name.Value == "id" {
+					v.report(field.Name.Loc, "%s can't be batched, it's the global id of a node", name)
+				} else if _, ok := field.Type.(*ast.NonNull); ok == true {
+					// graphql-go fails the whole response instead of the
+					// parent when a non-null field resolved by a thunk fails
+					v.report(field.Name.Loc, "%s can't be batched, batched fields must be nullable", name)
+				}
+			}
+		}
+	}
+}
+
+// declaringInterface returns the interface of an object that declares the
+// field, or an empty string
+func (v *schemaValidator) declaringInterface(object *ast.ObjectDefinition, field string) string {
+	for _, named := range object.Interfaces {
+		iface, ok := v.types[named.Name.Value].(*ast.InterfaceDefinition)
+		if ok == true && named.Name.Value != "Node" && interfaceField(iface, field) != nil {
+			return named.Name.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,334 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// connectionDirective marks a field as a relay connection, the connection and
+// edge types are generated when they are not defined in the schema
+const connectionDirective = "connection"
+
+// paginationSDL declares the relay pagination arguments that are added to a
+// connection field which doesn't declare any of them
+const paginationSDL = `type Connection {
+  field(
+    "Returns the first n items from the list"
+    first: Int
+    "Returns the items after the cursor"
+    after: String
+    "Returns the last n items from the list"
+    last: Int
+    "Returns the items before the cursor"
+    before: String
+  ): Int
+}`
+
+// paginationArgumentTypes are the types of the relay pagination arguments
+var paginationArgumentTypes = map[string]string{
+	"first":  "Int",
+	"after":  "String",
+	"last":   "Int",
+	"before": "String",
+}
+
+var paginationArguments = parsePaginationArguments()
+
+func parsePaginationArguments() []*ast.InputValueDefinition {
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{
+			Body: []byte(paginationSDL),
+			Name: "pagination arguments",
+		}),
+	})
+	if err != nil {
+		panic(err)
+	}
+	return doc.Definitions[0].(*ast.ObjectDefinition).Fields[0].Arguments
+}
+
+// pageInfoFields are the fields of the relay PageInfo type
+var pageInfoFields = map[string]string{
+	"hasNextPage":     "Boolean!",
+	"hasPreviousPage": "Boolean!",
+	"startCursor":     "String",
+	"endCursor":       "String",
+}
+
+// ConnectionDefinition is an object type that follows the relay connection
+// spec, i.e. it has a list of edges and a pageInfo field. Any other field of
+// the type is a field of the generated connection struct
+type ConnectionDefinition struct {
+	*ast.ObjectDefinition
+	Edge     *ast.ObjectDefinition
+	NodeType ast.Node
+}
+
+func (con ConnectionDefinition) GetKind() string {
+	return "ConnectionDefinition"
+}
+
+// EdgeDefinition is the edge type of a connection, it has a node and a cursor
+// field
+type EdgeDefinition struct {
+	*ast.ObjectDefinition
+	NodeType ast.Node
+}
+
+func (edge EdgeDefinition) GetKind() string {
+	return "EdgeDefinition"
+}
+
+// PageInfoDefinition is the relay PageInfo type used by connections
+type PageInfoDefinition struct {
+	*ast.ObjectDefinition
+}
+
+func (info PageInfoDefinition) GetKind() string {
+	return "PageInfoDefinition"
+}
+
+func definitionField(object *ast.ObjectDefinition, name string) *ast.FieldDefinition {
+	for _, field := range object.Fields {
+		if field.Name.Value == name {
+			return field
+		}
+	}
+	return nil
+}
+
+func isListType(t ast.Type) bool {
+	if nonnull, ok := t.(*ast.NonNull); ok == true {
+		t = nonnull.Type
+	}
+	_, ok := t.(*ast.List)
+	return ok
+}
+
+// connectionParts returns the edge and page info types of an object when it
+// is a connection
+func connectionParts(definitions []ast.Node, object *ast.ObjectDefinition) (edge *ast.ObjectDefinition, pageInfo *ast.ObjectDefinition, ok bool) {
+	edges := definitionField(object, "edges")
+	info := definitionField(object, "pageInfo")
+	if edges == nil || info == nil || isListType(edges.Type) == false {
+		return nil, nil, false
+	}
+
+	edgeName, infoName := namedType(edges.Type), namedType(info.Type)
+	if edgeName == nil || infoName == nil {
+		return nil, nil, false
+	}
+
+	edge, _ = NodeByName(definitions, edgeName.Name.Value).(*ast.ObjectDefinition)
+	pageInfo, _ = NodeByName(definitions, infoName.Name.Value).(*ast.ObjectDefinition)
+	if edge == nil || pageInfo == nil {
+		return nil, nil, false
+	}
+	if definitionField(edge, "node") == nil || definitionField(edge, "cursor") == nil {
+		return nil, nil, false
+	}
+
+	return edge, pageInfo, true
+}
+
+// connectionNodes finds the connections in the definitions, the returned map
+// holds the connection, edge and page info definitions by name
+func connectionNodes(definitions []ast.Node) map[string]ast.Node {
+	nodes := make(map[string]ast.Node)
+	for _, def := range definitions {
+		object, ok := def.(*ast.ObjectDefinition)
+		if ok == false {
+			continue
+		}
+
+		edge, pageInfo, ok := connectionParts(definitions, object)
+		if ok == false {
+			continue
+		}
+
+		nodeType := NodeByName(definitions, namedType(definitionField(edge, "node").Type).Name.Value)
+
+		nodes[object.Name.Value] = ConnectionDefinition{
+			ObjectDefinition: object,
+			Edge:             edge,
+			NodeType:         nodeType,
+		}
+		nodes[edge.Name.Value] = EdgeDefinition{
+			ObjectDefinition: edge,
+			NodeType:         nodeType,
+		}
+		nodes[pageInfo.Name.Value] = PageInfoDefinition{
+			ObjectDefinition: pageInfo,
+		}
+	}
+	return nodes
+}
+
+func fieldDirective(field *ast.FieldDefinition, name string) *ast.Directive {
+	for _, directive := range field.Directives {
+		if directive.Name.Value == name {
+			return directive
+		}
+	}
+	return nil
+}
+
+func directiveArgument(directive *ast.Directive, name string) (string, bool) {
+	for _, arg := range directive.Arguments {
+		if value, ok := arg.Value.(*ast.StringValue); ok == true && arg.Name.Value == name {
+			return value.Value, true
+		}
+	}
+	return "", false
+}
+
+// addConnectionTypes adds the connection and edge types of the fields marked
+// with @connection that are not defined in the schema, and the PageInfo type
+// when it's missing. The node type is set with @connection(node: "User"), or
+// derived from the name of the connection type, e.g. UserConnection.
+// Connections without a node type are returned as diagnostics
+func addConnectionTypes(doc *ast.Document) ([]Diagnostic, error) {
+	var diagnostics []Diagnostic
+	var sdl strings.Builder
+	generated := make(map[string]bool)
+
+	for _, def := range doc.Definitions {
+		var fields []*ast.FieldDefinition
+		switch def := def.(type) {
+		case *ast.ObjectDefinition:
+			fields = def.Fields
+		case *ast.InterfaceDefinition:
+			fields = def.Fields
+		}
+
+		for _, field := range fields {
+			directive := fieldDirective(field, connectionDirective)
+			named := namedType(field.Type)
+			if directive == nil || named == nil {
+				continue
+			}
+
+			name := named.Name.Value
+			if generated[name] == true || NodeByName(doc.Definitions, name) != nil {
+				continue
+			}
+
+			nodeName, ok := directiveArgument(directive, "node")
+			if ok == false {
+				nodeName = strings.TrimSuffix(name, "Connection")
+			}
+			if nodeName == name || NodeByName(doc.Definitions, nodeName) == nil {
+				diagnostics = append(diagnostics, Diagnostic{
+					Position: locate(directive.Loc),
+					Message: fmt.Sprintf("can't find the node type of the connection '%s', set it with @connection(node: \"Type\")",
+						name),
+				})
+				continue
+			}
+
+			edgeName := strings.TrimSuffix(name, "Connection") + "Edge"
+			fmt.Fprintf(&sdl, "\"A connection to a list of %s\"\n", nodeName)
+			fmt.Fprintf(&sdl, "type %s {\n  edges: [%s]\n  pageInfo: PageInfo!\n}\n\n", name, edgeName)
+			if NodeByName(doc.Definitions, edgeName) == nil {
+				fmt.Fprintf(&sdl, "\"An edge in a connection to a list of %s\"\n", nodeName)
+				fmt.Fprintf(&sdl, "type %s {\n  node: %s\n  cursor: String!\n}\n\n", edgeName, nodeName)
+			}
+			generated[name] = true
+		}
+	}
+
+	if len(generated) == 0 {
+		return diagnostics, nil
+	}
+
+	if NodeByName(doc.Definitions, "PageInfo") == nil {
+		sdl.WriteString("\"Information about pagination in a connection\"\ntype PageInfo {\n")
+		for _, name := range []string{"hasNextPage", "hasPreviousPage", "startCursor", "endCursor"} {
+			fmt.Fprintf(&sdl, "  %s: %s\n", name, pageInfoFields[name])
+		}
+		sdl.WriteString("}\n")
+	}
+
+	src := source.NewSource(&source.Source{
+		Body: []byte(sdl.String()),
+		Name: "generated connections",
+	})
+	generatedDoc, err := parser.Parse(parser.ParseParams{Source: src})
+	if err != nil {
+		return nil, syntaxError(src, err)
+	}
+
+	doc.Definitions = append(doc.Definitions, generatedDoc.Definitions...)
+	return diagnostics, nil
+}
+
+func isPaginationArgument(name string) bool {
+	_, ok := paginationArgumentTypes[name]
+	return ok
+}
+
+// connectionArguments returns the arguments of a connection field, the relay
+// pagination arguments are added when the field doesn't declare any of them
+func connectionArguments(field *ast.FieldDefinition) []*ast.InputValueDefinition {
+	for _, arg := range field.Arguments {
+		if isPaginationArgument(arg.Name.Value) {
+			return field.Arguments
+		}
+	}
+
+	args := make([]*ast.InputValueDefinition, 0, len(paginationArguments)+len(field.Arguments))
+	args = append(args, paginationArguments...)
+	return append(args, field.Arguments...)
+}
+
+// connectionFields returns the connection fields declared by an object or
+// interface, fields inherited from an interface are left out since their
+// arguments belong to the interface
+func (gen *Generator) connectionFields(parent ast.Node) []*ast.FieldDefinition {
+	var fields []*ast.FieldDefinition
+	switch parent := parent.(type) {
+	case *ast.ObjectDefinition:
+		for _, field := range parent.Fields {
+			if gen.isConnection(field.Type) && gen.isInheritedField(parent, field.Name.Value) == false {
+				fields = append(fields, field)
+			}
+		}
+	case *ast.InterfaceDefinition:
+		for _, field := range parent.Fields {
+			if gen.isConnection(field.Type) {
+				fields = append(fields, field)
+			}
+		}
+	}
+	return fields
+}
+
+// connectionArgumentsName returns the name of the argument struct of a
+// connection field, e.g. UserTodosArgs. Inherited fields use the struct of the
+// interface that declares them
+func (gen *Generator) connectionArgumentsName(parent ast.Node, field *ast.FieldDefinition) string {
+	name := parent.(namedDefinition).GetName().Value
+	if object, ok := parent.(*ast.ObjectDefinition); ok == true {
+		for _, iface := range object.Interfaces {
+			def, ok := gen.NamedLookup(iface.Name.Value).(*ast.InterfaceDefinition)
+			if ok == true && iface.Name.Value != "Node" && interfaceField(def, field.Name.Value) != nil {
+				name = iface.Name.Value
+				break
+			}
+		}
+	}
+	return name + public(field.Name.Value) + "Args"
+}
+
+func interfaceField(iface *ast.InterfaceDefinition, name string) *ast.FieldDefinition {
+	for _, field := range iface.Fields {
+		if field.Name.Value == name {
+			return field
+		}
+	}
+	return nil
+}
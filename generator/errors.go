@@ -0,0 +1,140 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// Position is a location in a schema file
+type Position struct {
+	File   string
+	Line   int
+	Column int
+}
+
+func (pos Position) String() string {
+	return fmt.Sprintf("%s:%d:%d", pos.File, pos.Line, pos.Column)
+}
+
+// ConfigError is returned when the project config, the language config or
+// the schema files listed in the project config can't be loaded
+type ConfigError struct {
+	Path string
+	Err  error
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("config %s: %s", e.Path, e.Err)
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// SyntaxError is returned when a schema can't be parsed
+type SyntaxError struct {
+	Position
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s: syntax error: %s", e.Position, e.Message)
+}
+
+// UnknownTypeError is returned when the schema references a type that is not
+// defined
+type UnknownTypeError struct {
+	Position
+	Name string
+}
+
+func (e *UnknownTypeError) Error() string {
+	return fmt.Sprintf("%s: type '%s' is not defined", e.Position, e.Name)
+}
+
+// ValueError is returned when a default value in the schema doesn't match
+// its type
+type ValueError struct {
+	Position
+	Message string
+}
+
+func (e *ValueError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Position, e.Message)
+}
+
+func valueError(loc *ast.Location, format string, args ...interface{}) error {
+	return &ValueError{
+		Position: locate(loc),
+		Message:  fmt.Sprintf(format, args...),
+	}
+}
+
+// TemplateError is returned when a template fails to execute
+type TemplateError struct {
+	Template string
+	Err      error
+}
+
+func (e *TemplateError) Error() string {
+	return fmt.Sprintf("template %s: %s", e.Template, e.Err)
+}
+
+func (e *TemplateError) Unwrap() error {
+	return e.Err
+}
+
+// positionAt converts an offset in a schema source to a position in the
+// schema file
+func positionAt(src *source.Source, offset int) Position {
+	if src == nil {
+		return Position{File: "Schema"}
+	}
+
+	if offset > len(src.Body) {
+		offset = len(src.Body)
+	}
+
+	text := string(src.Body[:offset])
+	line := strings.Count(text, "\n") + 1
+	column := len(text) - strings.LastIndex(text, "\n")
+
+	return Position{
+		File:   src.Name,
+		Line:   line,
+		Column: column,
+	}
+}
+
+// locate returns the position of an ast location, every schema file is
+// parsed as its own source so the location knows which file it belongs to
+func locate(loc *ast.Location) Position {
+	if loc == nil {
+		return Position{File: "Schema"}
+	}
+	return positionAt(loc.Source, loc.Start)
+}
+
+// syntaxError converts a parser error to a SyntaxError
+func syntaxError(src *source.Source, err error) error {
+	gqlerr, ok := err.(*gqlerrors.Error)
+	if ok == false || len(gqlerr.Positions) == 0 {
+		return err
+	}
+
+	// The parser message is formatted as "Syntax Error <source> (<line>:<col>)
+	// <description>" followed by the highlighted source
+	message := strings.SplitN(gqlerr.Message, "\n", 2)[0]
+	if index := strings.Index(message, ") "); index != -1 {
+		message = message[index+2:]
+	}
+
+	return &SyntaxError{
+		Position: positionAt(src, gqlerr.Positions[0]),
+		Message:  message,
+	}
+}
@@ -0,0 +1,73 @@
+package generator
+
+import (
+	"fmt"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// mergeExtensions merges every `extend type` definition into the type it
+// extends and removes the extensions from the document, so the templates only
+// see the complete types. The base type can be defined in any schema file,
+// extensions that can't be merged are returned as diagnostics
+func mergeExtensions(doc *ast.Document) []Diagnostic {
+	var definitions []ast.Node
+	var extensions []*ast.TypeExtensionDefinition
+
+	for _, def := range doc.Definitions {
+		if extension, ok := def.(*ast.TypeExtensionDefinition); ok == true {
+			extensions = append(extensions, extension)
+			continue
+		}
+		definitions = append(definitions, def)
+	}
+
+	var diagnostics []Diagnostic
+	for _, extension := range extensions {
+		name := extension.Definition.Name
+
+		base := NodeByName(definitions, name.Value)
+		if base == nil {
+			diagnostics = append(diagnostics, Diagnostic{
+				Position: locate(name.Loc),
+				Message:  fmt.Sprintf("can't extend type '%s', the type is not defined", name.Value),
+			})
+			continue
+		}
+
+		object, ok := base.(*ast.ObjectDefinition)
+		if ok == false {
+			diagnostics = append(diagnostics, Diagnostic{
+				Position: locate(name.Loc),
+				Message: fmt.Sprintf("can't extend type '%s', only object types can be extended",
+					name.Value),
+			})
+			continue
+		}
+
+		mergeObject(object, extension.Definition)
+	}
+
+	doc.Definitions = definitions
+	return diagnostics
+}
+
+// mergeObject adds the fields, interfaces and directives of the extension to
+// the object. Duplicate fields are left for the validation to report
+func mergeObject(object *ast.ObjectDefinition, extension *ast.ObjectDefinition) {
+	object.Fields = append(object.Fields, extension.Fields...)
+	object.Directives = append(object.Directives, extension.Directives...)
+
+	for _, iface := range extension.Interfaces {
+		implemented := false
+		for _, existing := range object.Interfaces {
+			if existing.Name.Value == iface.Name.Value {
+				implemented = true
+				break
+			}
+		}
+		if implemented == false {
+			object.Interfaces = append(object.Interfaces, iface)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/granateio/granate/generator/utils"
+)
+
+// GoFormat is the formatter command that formats go code in-process using
+// go/format, no external tool is required
+const GoFormat = "go/format"
+
+// outputPlaceholder is replaced with the path of the generated file in the
+// formatter arguments
+const outputPlaceholder = "{{output}}"
+
+// FormatterConfig defines the program used for formatting the generated code.
+// If one of the arguments contains {{output}} the formatter is expected to
+// format the file in place, otherwise the code is piped through the formatter
+type FormatterConfig struct {
+	CMD  string
+	Args []string
+}
+
+// FormatError is returned when the generated code can't be formatted, this
+// usually means the templates produced invalid code
+type FormatError struct {
+	Path        string
+	Diagnostics string
+	Source      []byte
+}
+
+var diagnosticLine = regexp.MustCompile(`(?m)(?:^|:)(\d+):\d+:`)
+
+func (e *FormatError) Error() string {
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "formatting %s failed:\n%s\n", e.Path,
+		strings.TrimSpace(e.Diagnostics))
+
+	lines := strings.Split(string(e.Source), "\n")
+
+	// Show the source surrounding each reported line, or the entire source
+	// if the formatter didn't report any line numbers
+	shown := make(map[int]bool)
+	for _, match := range diagnosticLine.FindAllStringSubmatch(e.Diagnostics, -1) {
+		line, _ := strconv.Atoi(match[1])
+		for i := line - 3; i <= line+3; i++ {
+			shown[i] = true
+		}
+	}
+
+	fmt.Fprintf(&msg, "\ngenerated source:\n")
+	for i, line := range lines {
+		if len(shown) > 0 && shown[i+1] == false {
+			continue
+		}
+		fmt.Fprintf(&msg, "%5d  %s\n", i+1, line)
+	}
+
+	return msg.String()
+}
+
+// Format formats the generated source of the file at path
+func (formatter FormatterConfig) Format(path string, src []byte) ([]byte, error) {
+	switch formatter.CMD {
+	case "":
+		return src, nil
+	case GoFormat:
+		out, err := utils.FormatGo(path, src)
+		if err != nil {
+			return nil, &FormatError{Path: path, Diagnostics: err.Error(), Source: src}
+		}
+		return out, nil
+	}
+
+	inplace := false
+	args := make([]string, len(formatter.Args))
+	for i, arg := range formatter.Args {
+		if strings.Contains(arg, outputPlaceholder) {
+			inplace = true
+		}
+		args[i] = strings.Replace(arg, outputPlaceholder, path, -1)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(formatter.CMD, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if inplace == true {
+		if err := ioutil.WriteFile(path, src, 0644); err != nil {
+			return nil, err
+		}
+	} else {
+		cmd.Stdin = bytes.NewReader(src)
+	}
+
+	if err := cmd.Run(); err != nil {
+		return nil, &FormatError{
+			Path:        path,
+			Diagnostics: strings.TrimSpace(err.Error() + "\n" + stderr.String()),
+			Source:      src,
+		}
+	}
+
+	if inplace == true {
+		return ioutil.ReadFile(path)
+	}
+	return stdout.Bytes(), nil
+}
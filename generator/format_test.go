@@ -0,0 +1,139 @@
+package generator
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name      string
+		formatter FormatterConfig
+		src       string
+		out       string
+		err       []string
+	}{
+		{
+			name:      "no formatter",
+			formatter: FormatterConfig{},
+			src:       "package schema\nimport \"errors\"\n",
+			out:       "package schema\nimport \"errors\"\n",
+		},
+		{
+			name:      "go/format",
+			formatter: FormatterConfig{CMD: GoFormat},
+			src:       "package schema\nimport \"errors\"\nvar x   = 1\n",
+			out:       "package schema\n\nvar x = 1\n",
+		},
+		{
+			name:      "go/format syntax error",
+			formatter: FormatterConfig{CMD: GoFormat},
+			src:       "package schema\n\nfunc f( {\n}\n",
+			err: []string{
+				"formatting schema/provider.go failed:\nschema/provider.go:3:9: ",
+				"    3  func f( {\n",
+			},
+		},
+		{
+			name:      "missing command",
+			formatter: FormatterConfig{CMD: "granate-missing-formatter"},
+			src:       "package schema\n",
+			err:       []string{"formatting schema/provider.go failed:\n", "    1  package schema\n"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out, err := test.formatter.Format("schema/provider.go", []byte(test.src))
+			if len(test.err) > 0 {
+				var formatErr *FormatError
+				if errors.As(err, &formatErr) == false {
+					t.Fatalf("expected a FormatError, got %T: %v", err, err)
+				}
+				for _, part := range test.err {
+					if strings.Contains(err.Error(), part) == false {
+						t.Errorf("error %q doesn't contain %q", err, part)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(out) != test.out {
+				t.Errorf("output %q, expected %q", out, test.out)
+			}
+		})
+	}
+}
+
+func TestFormatCommand(t *testing.T) {
+	if _, err := exec.LookPath("cat"); err != nil {
+		t.Skip("the cat command is not available")
+	}
+
+	formatter := FormatterConfig{CMD: "cat"}
+	out, err := formatter.Format("schema/provider.go", []byte("package schema\n"))
+	if err != nil || string(out) != "package schema\n" {
+		t.Errorf("output %q, error %v, expected the source piped through the command", out, err)
+	}
+}
+
+func TestGenerateFormatError(t *testing.T) {
+	templates := t.TempDir()
+	files := map[string]string{
+		"config.yaml": `language:
+  scalars:
+    String: string
+templates:
+  - Broken
+formatter:
+  cmd: "go/format"
+`,
+		"broken.tmpl": `{{define "Broken"}}{{ startfile (print output.target "broken.go") }}package broken
+
+func broken( {
+}
+{{ endfile }}{{end}}`,
+		"schema.graphql": "type Query {\n    name: String\n}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(templates, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dir, err := os.MkdirTemp("testdata", "generated-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	dir = filepath.ToSlash(dir)
+
+	gen, err := NewFromConfig(ProjectConfig{
+		Schemas:     []string{filepath.Join(templates, "schema.graphql")},
+		Language:    "go",
+		TemplateDir: templates,
+		Output:      map[string]string{"target": dir + "/"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = gen.Generate()
+	var templateErr *TemplateError
+	if errors.As(err, &templateErr) == false || templateErr.Template != "Broken" {
+		t.Fatalf("expected a TemplateError of Broken, got %T: %v", err, err)
+	}
+	var formatErr *FormatError
+	if errors.As(err, &formatErr) == false {
+		t.Fatalf("expected the TemplateError to wrap a FormatError, got %v", err)
+	}
+	if position := dir + "/broken.go:3:14: "; strings.Contains(formatErr.Diagnostics, position) == false {
+		t.Errorf("diagnostics %q don't contain the position %q", formatErr.Diagnostics, position)
+	}
+}
@@ -2,8 +2,9 @@ package generator
 
 import (
 	"bytes"
-	"log"
+	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"text/template"
 
@@ -14,36 +15,58 @@ import (
 
 func (gen *Generator) funcMap() template.FuncMap {
 	return template.FuncMap{
-		"cfg":           gen.getConfig,
-		"graphqltype":   gen.graphqltype,
-		"nativetype":    gen.nativetype,
-		"nativetypepkg": gen.nativetypepkg,
-		"nodes":         gen.getNodes,
-		"output":        gen.getOutput,
-		"root":          gen.isRootField,
-		"namedtype":     gen.getNamedType,
+		"cfg":              gen.getConfig,
+		"graphqltype":      gen.graphqltype,
+		"nativetype":       gen.nativetype,
+		"nativetypepkg":    gen.nativetypepkg,
+		"decoder":          gen.decoder,
+		"inputtype":        gen.inputtype,
+		"listitem":         gen.listItemType,
+		"nodes":            gen.getNodes,
+		"output":           gen.getOutput,
+		"root":             gen.isRootField,
+		"rootof":           gen.isRootOf,
+		"operations":       gen.getRootOperations,
+		"operationtype":    gen.OperationType,
+		"namedtype":        gen.getNamedType,
+		"implements":       gen.getImplementations,
+		"member":           gen.isAbstractMember,
+		"scalarimports":    gen.getScalarImports,
+		"inherited":        gen.isInheritedField,
+		"connectionargs":   connectionArguments,
+		"connectionfields": gen.connectionFields,
+		"argsname":         gen.connectionArgumentsName,
+		"paginationarg":    isPaginationArgument,
+		"batched":          gen.isBatched,
+		"batchfields":      gen.batchFields,
+		"batchfield":       batchField,
 
 		// Move to utils package?
 		"body":         getBody,
 		"desc":         getDescription,
+		"deprecated":   getDeprecation,
+		"argdesc":      getArgumentDescription,
+		"defaultvalue": gen.defaultValue,
 		"kind":         getKind,
 		"private":      private,
 		"public":       public,
+		"camel":        camel,
 		"relay":        isRelayInterface,
-		"connection":   isRelayConnection,
+		"connection":   gen.isConnection,
 		"relayinput":   gen.isRelayInput,
 		"relaypayload": gen.isRelayPayload,
 
 		// Userful string functions
-		"suffix": strings.HasSuffix,
-		"prefix": strings.HasPrefix,
+		"suffix":     strings.HasSuffix,
+		"prefix":     strings.HasPrefix,
+		"trimprefix": strings.TrimPrefix,
 
 		"existfile": fileExists,
 		// Placeholder functions, these functions will be replaced with a local
 		// representation in each go routine for every main template
 		"startfile": func() string { return "" },
-		"endfile":   func() string { return "" },
-		"partial":   func() string { return "" },
+		"endfile":   func() (string, error) { return "", nil },
+		"partial":   func() (string, error) { return "", nil },
 	}
 }
 
@@ -67,9 +90,102 @@ func isRelayInterface(iface []*ast.Named) bool {
 	return false
 }
 
-func isRelayConnection(t ast.Type) bool {
-	name := getBody(t)
-	return strings.HasSuffix(name, "Connection")
+// getImplementations returns every object definition that implements the
+// interface with the given name
+func (gen *Generator) getImplementations(name string) []ast.Node {
+	var objects []ast.Node
+	for _, node := range gen.Nodes.Object {
+		object := node.(*ast.ObjectDefinition)
+		for _, iface := range object.Interfaces {
+			if iface.Name.Value == name {
+				objects = append(objects, node)
+				break
+			}
+		}
+	}
+	return objects
+}
+
+// isAbstractMember checks if the object implements an interface or is a
+// member of a union. Adapters of these objects have a marker method, since
+// the adapter interfaces alone can't tell the objects apart when one has a
+// subset of the methods of another
+func (gen *Generator) isAbstractMember(object *ast.ObjectDefinition) bool {
+	if len(object.Interfaces) > 0 {
+		return true
+	}
+	for _, node := range gen.Nodes.Definition {
+		union, ok := node.(*ast.UnionDefinition)
+		if ok == false {
+			continue
+		}
+		for _, member := range union.Types {
+			if member.Name.Value == object.Name.Value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isInheritedField checks if the field is declared by one of the interfaces
+// the object implements, the native method is then provided by the embedded
+// interface adapter
+func (gen *Generator) isInheritedField(object *ast.ObjectDefinition, field string) bool {
+	for _, iface := range object.Interfaces {
+		if iface.Name.Value == "Node" {
+			continue
+		}
+		def, ok := gen.NamedLookup(iface.Name.Value).(*ast.InterfaceDefinition)
+		if ok == false {
+			continue
+		}
+		for _, f := range def.Fields {
+			if f.Name.Value == field {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// splitNativeType splits a native type from the project config into the
+// import path and the type as used in the code, e.g. net/url.URL is split into
+// net/url and url.URL
+func splitNativeType(native string) (string, string) {
+	typeName := strings.TrimLeft(native, "*[]")
+	prefix := native[:len(native)-len(typeName)]
+
+	dot := strings.LastIndex(typeName, ".")
+	if dot == -1 {
+		return "", native
+	}
+
+	importPath := typeName[:dot]
+	return importPath, prefix + utils.ImportName(importPath) + typeName[dot:]
+}
+
+// getScalarImports returns the import paths required by the native types of
+// the custom scalars
+func (gen *Generator) getScalarImports() []string {
+	var imports []string
+	seen := make(map[string]bool)
+	for _, native := range gen.Config.Scalars {
+		importPath, _ := splitNativeType(native)
+		if importPath == "" || seen[importPath] == true {
+			continue
+		}
+		seen[importPath] = true
+		imports = append(imports, importPath)
+	}
+	sort.Strings(imports)
+	return imports
+}
+
+// isConnection checks if the type is a relay connection, see connectionNodes
+func (gen *Generator) isConnection(t ast.Type) bool {
+	node := gen.NamedLookup(gen.getNamedType(t))
+	return node != nil && node.GetKind() == "ConnectionDefinition"
 }
 
 func (gen *Generator) isRelayInput(input string) bool {
@@ -92,7 +208,11 @@ func (gen *Generator) validateRelayMutationType(name string, mt MutationType) bo
 		return false
 	}
 	srcmut := strings.ToLower(strings.TrimSuffix(name, string(mt)))
-	mutnode := gen.NamedLookup("Mutation")
+	mutation := gen.OperationType("mutation")
+	if mutation == "" {
+		return false
+	}
+	mutnode := gen.NamedLookup(mutation)
 	if mutnode == nil {
 		return false
 	}
@@ -119,50 +239,169 @@ func private(name string) string {
 	return index + name[1:]
 }
 
-// TODO: Load root functions from language config
+// camel converts a name like IN_PROGRESS or in_progress to InProgress, names
+// that are not all upper case keep their casing
+func camel(name string) string {
+	upper := strings.ToUpper(name) == name
+	var output strings.Builder
+	for _, part := range strings.Split(name, "_") {
+		if part == "" {
+			continue
+		}
+		if upper == true {
+			part = strings.ToLower(part)
+		}
+		output.WriteString(public(part))
+	}
+	return output.String()
+}
+
 func (gen *Generator) isRootField(name string) bool {
-	return gen.LangConf.IsRoot(name)
+	return gen.IsRoot(name)
 }
 
-func (gen *Generator) getNodes() astNodes {
-	return gen.Nodes
+// isRootOf checks if the type with the given name is the root type of the
+// operation, i.e. query, mutation or subscription
+func (gen *Generator) isRootOf(operation, name string) bool {
+	return gen.Operations[operation] == name
 }
 
-func (gen *Generator) definition(name string) string {
-	var output bytes.Buffer
-	gen.Template.ExecuteTemplate(
-		&output, "Graphql"+gen.NamedLookup(name).GetKind(), map[string]string{
-			"Name": name,
-		})
-	return output.String()
+// RootOperation is an operation of the schema together with its root type
+type RootOperation struct {
+	Operation  string
+	Definition *ast.ObjectDefinition
+}
+
+// getRootOperations returns the operations of the schema in the order query,
+// mutation and subscription, operations without a root type are left out
+func (gen *Generator) getRootOperations() []RootOperation {
+	var operations []RootOperation
+	for _, operation := range operationNames {
+		root, ok := gen.NamedLookup(gen.Operations[operation]).(*ast.ObjectDefinition)
+		if ok == true {
+			operations = append(operations, RootOperation{
+				Operation:  operation,
+				Definition: root,
+			})
+		}
+	}
+	return operations
+}
+
+func (gen *Generator) getNodes() astNodes {
+	return gen.Nodes
 }
 
 func (gen *Generator) getConfig() interface{} {
 	return gen.TmplConf
 }
 
+// describedNode is a definition that can have a description string
+type describedNode interface {
+	GetDescription() *ast.StringValue
+}
+
+// getBody returns the source of a node, without the description string
 func getBody(n ast.Node) string {
-	body := n.GetLoc().Source.Body
-	return string(body[n.GetLoc().Start:n.GetLoc().End])
+	loc := n.GetLoc()
+	start := loc.Start
+	if described, ok := n.(describedNode); ok == true {
+		if desc := described.GetDescription(); desc != nil && desc.Loc != nil {
+			start = desc.Loc.End
+		}
+	}
+	return strings.TrimSpace(string(loc.Source.Body[start:loc.End]))
 }
 
+// getDescription returns the lines of the description string, or of the #
+// comment block above the definition when it has no description string
 func getDescription(n ast.Node) []string {
+	if described, ok := n.(describedNode); ok == true {
+		if desc := described.GetDescription(); desc != nil {
+			return strings.Split(strings.TrimSpace(desc.Value), "\n")
+		}
+	}
 	return utils.GetCommentBlock(n.GetLoc().Source.Body, n.GetLoc().Start)
 }
 
-func (gen *Generator) nativetypepkg(def interface{}, pkg string) string {
+// defaultDeprecationReason is the reason used by graphql-go when the
+// @deprecated directive has no reason argument
+const defaultDeprecationReason = "No longer supported"
+
+// getDeprecation returns the reason of the @deprecated directive on a field,
+// argument or enum value, or an empty string when it's not deprecated
+func getDeprecation(n ast.Node) string {
+	var directives []*ast.Directive
+	switch n := n.(type) {
+	case *ast.FieldDefinition:
+		directives = n.Directives
+	case *ast.InputValueDefinition:
+		directives = n.Directives
+	case *ast.EnumValueDefinition:
+		directives = n.Directives
+	}
+
+	for _, directive := range directives {
+		if directive.Name.Value != "deprecated" {
+			continue
+		}
+		for _, arg := range directive.Arguments {
+			if value, ok := arg.Value.(*ast.StringValue); ok == true && arg.Name.Value == "reason" {
+				return value.Value
+			}
+		}
+		return defaultDeprecationReason
+	}
+
+	return ""
+}
+
+// getArgumentDescription returns the description of an argument, graphql-go
+// can't deprecate arguments so the deprecation reason is added to the
+// description instead
+func getArgumentDescription(n ast.Node) []string {
+	desc := getDescription(n)
+	if reason := getDeprecation(n); reason != "" {
+		desc = append(desc, "Deprecated: "+reason)
+	}
+	return desc
+}
+
+func (gen *Generator) nativetypepkg(def interface{}, pkg string) (string, error) {
 	return gen.def2Type(typeNative, def, pkg)
 }
 
-func (gen *Generator) nativetype(def interface{}) string {
+func (gen *Generator) nativetype(def interface{}) (string, error) {
 	return gen.def2Type(typeNative, def, "")
 }
 
-func (gen *Generator) graphqltype(def interface{}) string {
+// inputtype returns the native type of an argument or input field, nullable
+// values are pointers unless there is a default value to fall back on
+func (gen *Generator) inputtype(def *ast.InputValueDefinition, pkg string) (string, error) {
+	native, err := gen.nativetypepkg(def.Type, "*"+pkg)
+	if err != nil || def.DefaultValue == nil {
+		return native, err
+	}
+	return strings.TrimPrefix(native, "*"), nil
+}
+
+// listItemType returns the native type of the items of a list, the items
+// are nullable unless they are non-null themselves, whatever the nullability
+// of the list is. The list types of the adapters and the decoders both use it
+func (gen *Generator) listItemType(item ast.Type, pkg string) (string, error) {
+	return gen.nativetypepkg(item, "*"+strings.TrimPrefix(pkg, "*"))
+}
+
+// decoder returns the function that decodes an argument of the type
+func (gen *Generator) decoder(def interface{}) (string, error) {
+	return gen.def2Type(typeDecode, def, "")
+}
+
+func (gen *Generator) graphqltype(def interface{}) (string, error) {
 	return gen.def2Type(typeGraphql, def, "")
 }
 
-func (gen *Generator) def2Type(set typeClass, def interface{}, pkg string) string {
+func (gen *Generator) def2Type(set typeClass, def interface{}, pkg string) (string, error) {
 	switch t := def.(type) {
 	case *ast.Name:
 		return gen.getType(set, &ast.Named{
@@ -173,9 +412,7 @@ func (gen *Generator) def2Type(set typeClass, def interface{}, pkg string) strin
 		return gen.getType(set, t, pkg)
 	}
 
-	// TODO: Improve error message
-	log.Fatalf("Unsupported type %v", def)
-	return ""
+	return "", fmt.Errorf("unsupported type %T", def)
 }
 
 type typeClass string
@@ -183,6 +420,7 @@ type typeClass string
 const (
 	typeNative  typeClass = "Native"
 	typeGraphql typeClass = "Graphql"
+	typeDecode  typeClass = "Decode"
 )
 
 func (gen *Generator) getNamedType(t ast.Type) string {
@@ -208,16 +446,26 @@ func (gen *Generator) getNamedType(t ast.Type) string {
 }
 
 // TODO: Refactor/improve this method
-func (gen *Generator) getType(typeclass typeClass, t ast.Type, pkg string) string {
+func (gen *Generator) getType(typeclass typeClass, t ast.Type, pkg string) (string, error) {
 	class := string(typeclass)
+	var output bytes.Buffer
 	switch v := t.(type) {
 	case *ast.Named:
-		var output bytes.Buffer
 		l := v.Loc
 		name := string(l.Source.Body[l.Start:l.End])
 
 		namedType, ok := gen.LangConf.Language.Scalars[name]
 
+		// Custom scalars mapped to a native type in the project config are
+		// only represented by the native type, the graphql type is still
+		// generated from the scalar definition
+		if class != string(typeGraphql) {
+			if mappedType, mapped := gen.Config.Scalars[name]; mapped == true {
+				_, namedType = splitNativeType(mappedType)
+				ok = true
+			}
+		}
+
 		starprefix := ""
 		if strings.HasPrefix(pkg, "*") {
 			starprefix = "*"
@@ -227,40 +475,45 @@ func (gen *Generator) getType(typeclass typeClass, t ast.Type, pkg string) strin
 			if class == string(typeGraphql) {
 				namedType = name
 			}
-			gen.Template.ExecuteTemplate(&output, class+"Named", map[string]string{
-				"Name": starprefix + namedType,
+			err := gen.Template.ExecuteTemplate(&output, class+"Named", map[string]string{
+				"Name":   starprefix + namedType,
+				"Scalar": name,
 			})
-			return output.String()
+			return output.String(), err
+		}
+
+		node, err := gen.lookupType(name, l)
+		if err != nil {
+			return "", err
 		}
 
 		pkgprefix := ""
 		if pkg != "" {
 			pkgprefix = pkg + "."
 		}
-		gen.Template.ExecuteTemplate(&output,
-			class+gen.NamedLookup(name).GetKind(),
+		err = gen.Template.ExecuteTemplate(&output,
+			class+node.GetKind(),
 			map[string]string{
-				"Name": pkgprefix + name,
+				"Name":    pkgprefix + name,
+				"Pointer": starprefix,
 			},
 		)
 
-		return output.String()
+		return output.String(), err
 
 	case *ast.NonNull:
-		var output bytes.Buffer
 		l := v.Loc
 		val := string(l.Source.Body[l.Start : l.End-1])
 		newLoc := ast.NewLocation(l)
 		newLoc.End--
 		innerType := utils.ParseType(val, newLoc)
 
-		gen.Template.ExecuteTemplate(&output, class+"NonNull", map[string]interface{}{
+		err := gen.Template.ExecuteTemplate(&output, class+"NonNull", map[string]interface{}{
 			"Type":    innerType,
 			"Package": pkg,
 		})
-		return output.String()
+		return output.String(), err
 	case *ast.List:
-		var output bytes.Buffer
 		l := v.Loc
 		val := string(l.Source.Body[l.Start+1 : l.End-1])
 		newLoc := ast.NewLocation(l)
@@ -270,13 +523,13 @@ func (gen *Generator) getType(typeclass typeClass, t ast.Type, pkg string) strin
 
 		newType := utils.ParseType(val, newLoc)
 
-		gen.Template.ExecuteTemplate(&output, class+"List", map[string]interface{}{
+		err := gen.Template.ExecuteTemplate(&output, class+"List", map[string]interface{}{
 			"Type":    newType,
 			"Package": pkg,
 		})
 
-		return output.String()
+		return output.String(), err
 
 	}
-	return ""
+	return "", nil
 }
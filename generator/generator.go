@@ -2,12 +2,10 @@ package generator
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
-	"io"
 	"io/ioutil"
-	"log"
 	"os"
-	"os/exec"
 	"path"
 	"strings"
 	"sync"
@@ -17,6 +15,7 @@ import (
 
 	"github.com/granateio/granate/generator/utils"
 	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/kinds"
 	"github.com/graphql-go/graphql/language/parser"
 	"github.com/graphql-go/graphql/language/source"
 )
@@ -31,15 +30,38 @@ type Generator struct {
 	LangConf LanguageConfig
 	Nodes    astNodes
 
+	// Maps the operations (query, mutation and subscription) to the name of
+	// their root type, one type can be the root of several operations
+	Operations map[string]string
+
 	TmplConf map[string]string
+
+	// The fields from the batch section of the project config, as Type.field
+	batch map[string]bool
+
+	// Problems found while merging the extensions and adding the connection
+	// types, they are reported by Validate with the rest
+	diagnostics []Diagnostic
 }
 
 // ProjectConfig contains the granate.yaml information
 type ProjectConfig struct {
-	// TODO Support a globbing system
+	// Schema files, directories or glob patterns (** matches any number of
+	// directories)
 	Schemas  []string
 	Language string
 	Output   map[string]string
+
+	// Directory with a config.yaml and/or templates that overrides or
+	// extends the built-in language
+	TemplateDir string `yaml:"template_dir"`
+
+	// Maps custom scalars to existing native types, e.g. DateTime: time.Time
+	Scalars map[string]string
+
+	// Object fields that are loaded in batches, e.g. User.todos, fields can
+	// also be marked with the @batch directive
+	Batch []string
 }
 
 // LanguageConfig defines the language specific
@@ -60,10 +82,7 @@ type LanguageConfig struct {
 	Templates []string
 
 	// Program/command used for formatting the output code
-	Formatter struct {
-		CMD  string
-		Args []string
-	}
+	Formatter FormatterConfig
 }
 
 type OutputFileBuffer struct {
@@ -79,6 +98,7 @@ type TemplateFileFuncs struct {
 	BufferStack   *utils.Lifo
 	SwapBuffer    *utils.SwapBuffer
 	LocalTemplate *template.Template
+	Formatter     FormatterConfig
 	linenumber    int
 }
 
@@ -101,7 +121,7 @@ func (tmpl *TemplateFileFuncs) Start(path string) string {
 	return ""
 }
 
-func (tmpl *TemplateFileFuncs) End() string {
+func (tmpl *TemplateFileFuncs) End() (string, error) {
 
 	output, ok := tmpl.SwapBuffer.GetBuffer().(*OutputFileBuffer)
 
@@ -110,40 +130,29 @@ func (tmpl *TemplateFileFuncs) End() string {
 	}
 
 	if output.Path == "" {
-		return ""
+		return "", nil
 	}
 
-	// fmt.Println(output.GetBuffer().String())
-	// Unnecessary:
-	// tmpl.FileBuffers = append(tmpl.FileBuffers, output)
-
 	dir := path.Join(".", path.Dir(output.Path))
 	err := os.MkdirAll(dir, os.ModePerm)
-
 	if err != nil {
-		fmt.Println(err)
+		return "", err
 	}
 
-	// TODO: Read the fmt command from config
-	// cmd := exec.Command("gofmt")
-	cmd := exec.Command("goimports")
-	stdin, err := cmd.StdinPipe()
-	check(err)
-
-	go func() {
-		defer stdin.Close()
-		io.WriteString(stdin, output.GetBuffer().String())
-	}()
-
-	out, err := cmd.CombinedOutput()
+	out, err := tmpl.Formatter.Format(output.Path, output.GetBuffer().Bytes())
+	if err != nil {
+		// Keep the unformatted code around for inspection
+		ioutil.WriteFile(output.Path, output.GetBuffer().Bytes(), 0644)
+		return "", err
+	}
 
 	ln, _ := utils.LineCounter(bytes.NewReader(out))
 	tmpl.linenumber += ln
 
 	err = ioutil.WriteFile(output.Path, out, 0644)
-	// err = ioutil.WriteFile(output.Path, output.GetBuffer().Bytes(), 0644)
-
-	check(err)
+	if err != nil {
+		return "", err
+	}
 
 	prevBuffer, ok := tmpl.BufferStack.Pop().(utils.OpaqueBytesBuffer)
 	if ok == false {
@@ -152,7 +161,7 @@ func (tmpl *TemplateFileFuncs) End() string {
 
 	tmpl.SwapBuffer.SetBuffer(prevBuffer)
 
-	return ""
+	return "", nil
 }
 
 func (lang LanguageConfig) IsRoot(val string) bool {
@@ -165,55 +174,131 @@ func (lang LanguageConfig) IsRoot(val string) bool {
 	return false
 }
 
-// New creates a new Generator instance
-func New(config string) (*Generator, error) {
+// operationNames are the operations a schema can define, in the order they
+// are generated
+var operationNames = []string{"query", "mutation", "subscription"}
+
+// rootOperations maps the operations to the name of their root type. An
+// explicit schema definition takes precedence over the default root names
+// from the language config
+func (lang LanguageConfig) rootOperations(definitions []ast.Node) map[string]string {
+	operations := make(map[string]string)
+
+	for _, def := range definitions {
+		schemaDef, ok := def.(*ast.SchemaDefinition)
+		if ok == false {
+			continue
+		}
+
+		for _, operation := range schemaDef.OperationTypes {
+			operations[operation.Operation] = operation.Type.Name.Value
+		}
+		return operations
+	}
+
+	for _, root := range lang.Language.Root {
+		operations[strings.ToLower(root)] = root
+	}
+
+	return operations
+}
+
+// IsRoot checks if the type with the given name is a root operation type
+func (gen *Generator) IsRoot(name string) bool {
+	return len(gen.rootOperationsOf(name)) > 0
+}
+
+// rootOperationsOf returns the operations the type is the root type of
+func (gen *Generator) rootOperationsOf(name string) []string {
+	var operations []string
+	for _, operation := range operationNames {
+		if gen.Operations[operation] == name {
+			operations = append(operations, operation)
+		}
+	}
+	return operations
+}
+
+// OperationType returns the name of the root type used for the operation,
+// or an empty string if the schema does not define the operation
+func (gen *Generator) OperationType(operation string) string {
+	return gen.Operations[operation]
+}
+
+// LoadConfig reads a granate.yaml project config
+func LoadConfig(config string) (ProjectConfig, error) {
+	genCfg := ProjectConfig{}
 
 	confFile, err := ioutil.ReadFile(config)
 	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		return genCfg, err
 	}
 
-	genCfg := ProjectConfig{}
 	err = yaml.Unmarshal(confFile, &genCfg)
+	return genCfg, err
+}
+
+// New creates a new Generator instance
+func New(config string) (*Generator, error) {
+	genCfg, err := LoadConfig(config)
+	if err != nil {
+		return nil, &ConfigError{Path: config, Err: err}
+	}
+
+	return NewFromConfig(genCfg)
+}
+
+// NewFromConfig creates a new Generator instance from a project config
+func NewFromConfig(genCfg ProjectConfig) (*Generator, error) {
+	schemas, err := schemaFiles(genCfg.Schemas)
 	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		return nil, &ConfigError{Path: "schemas", Err: err}
 	}
 
-	// Combine all .graphql files into one schema
+	// Parse every schema file as its own source so locations point into the
+	// right file, and combine the definitions into one document
 	var schema bytes.Buffer
-	for _, scm := range genCfg.Schemas {
+	AST := &ast.Document{Kind: kinds.Document}
+	for _, scm := range schemas {
 		file, err := ioutil.ReadFile(scm)
-		check(err)
+		if err != nil {
+			return nil, &ConfigError{Path: scm, Err: err}
+		}
 		schema.Write(file)
-	}
-
-	// Create the generated package directory
-	// Ignore error for now
-	// err = os.Mkdir(genCfg.Package, 0766)
-
-	src := source.NewSource(&source.Source{
-		Body: schema.Bytes(),
-		Name: "Schema",
-	})
+		schema.WriteString("\n")
+
+		src := source.NewSource(&source.Source{
+			Body: file,
+			Name: scm,
+		})
+
+		doc, err := parser.Parse(parser.ParseParams{
+			Source: src,
+		})
+		if err != nil {
+			return nil, syntaxError(src, err)
+		}
 
-	AST, err := parser.Parse(parser.ParseParams{
-		Source: src,
-	})
+		AST.Definitions = append(AST.Definitions, doc.Definitions...)
+	}
 
-	check(err)
+	diagnostics := mergeExtensions(AST)
 
-	gopath := os.Getenv("GOPATH")
-	projectpath := gopath + "/src/github.com/granateio/granate/"
-	langpath := projectpath + "language/" + genCfg.Language + "/"
+	connectionDiagnostics, err := addConnectionTypes(AST)
+	if err != nil {
+		return nil, err
+	}
+	diagnostics = append(diagnostics, connectionDiagnostics...)
 
-	langConfigFile, err := ioutil.ReadFile(langpath + "config.yaml")
-	check(err)
+	batch, err := batchConfig(AST.Definitions, genCfg.Batch)
+	if err != nil {
+		return nil, &ConfigError{Path: "batch", Err: err}
+	}
 
-	langConfig := LanguageConfig{}
-	err = yaml.Unmarshal(langConfigFile, &langConfig)
-	check(err)
+	langConfig, err := loadLanguageConfig(genCfg.Language, genCfg.TemplateDir)
+	if err != nil {
+		return nil, &ConfigError{Path: "language " + genCfg.Language, Err: err}
+	}
 
 	gen := &Generator{
 		Schema:   schema.String(),
@@ -221,15 +306,19 @@ func New(config string) (*Generator, error) {
 		TmplConf: langConfig.Config,
 		Config:   genCfg,
 		LangConf: langConfig,
-	}
+		batch:    batch,
 
-	// gen.Nodes.Connection = make(map[string]ast.Node)
-
-	gen.Template, err = template.New("main").
-		Funcs(gen.funcMap()).
-		ParseGlob(langpath + "*.tmpl")
+		diagnostics: diagnostics,
+	}
 
-	check(err)
+	gen.Template, err = parseLanguageTemplates(
+		template.New("main").Funcs(gen.funcMap()),
+		genCfg.Language,
+		genCfg.TemplateDir,
+	)
+	if err != nil {
+		return nil, &TemplateError{Template: genCfg.Language, Err: err}
+	}
 
 	return gen, nil
 }
@@ -245,6 +334,8 @@ func (gen *Generator) NamedLookup(name string) ast.Node {
 }
 
 // TODO: Much the same as the NamedLookup function
+// NodeByName returns the definition with the given name, or nil if the
+// definition does not exist
 func NodeByName(nodes []ast.Node, name string) ast.Node {
 	for _, node := range nodes {
 		named, ok := node.(namedDefinition)
@@ -256,10 +347,21 @@ func NodeByName(nodes []ast.Node, name string) ast.Node {
 		}
 	}
 
-	log.Fatalf("Type with name '%s' is not defined", name)
 	return nil
 }
 
+// lookupType returns the definition of the type referenced at loc
+func (gen *Generator) lookupType(name string, loc *ast.Location) (ast.Node, error) {
+	node := gen.NamedLookup(name)
+	if node == nil {
+		return nil, &UnknownTypeError{
+			Position: locate(loc),
+			Name:     name,
+		}
+	}
+	return node, nil
+}
+
 type generatorPass struct {
 	Name string
 	File string
@@ -273,29 +375,17 @@ type astNodes struct {
 	Root       []ast.Node
 	Definition []ast.Node
 	Object     []ast.Node
+	Interface  []ast.Node
+	Scalar     []ast.Node
 	Relay      []ast.Node
 }
 
-type ConnectionDefinition struct {
-	Name     *ast.Name
-	Loc      *ast.Location
-	NodeType ast.Node
-}
-
-func (con ConnectionDefinition) GetKind() string {
-	return "ConnectionDefinition"
-}
-
-func (con ConnectionDefinition) GetLoc() *ast.Location {
-	return con.Loc
-}
-
-func (con ConnectionDefinition) GetName() *ast.Name {
-	return con.Name
-}
-
 // Generate starts the code generation process
-func (gen *Generator) Generate() {
+func (gen *Generator) Generate() error {
+	if err := gen.Validate(); err != nil {
+		return err
+	}
+
 	definitions := gen.Ast.Definitions
 
 	tmpl := gen.Template
@@ -303,7 +393,12 @@ func (gen *Generator) Generate() {
 
 	var wait sync.WaitGroup
 	var nodes astNodes
-	connections := make(map[string]bool)
+
+	gen.Operations = gen.LangConf.rootOperations(definitions)
+
+	// Connections, their edges and the PageInfo type are generated as
+	// structs instead of interfaces
+	connections := connectionNodes(definitions)
 
 	// Gather usefull definitions
 	for _, def := range definitions {
@@ -313,12 +408,23 @@ func (gen *Generator) Generate() {
 			continue
 		}
 
+		if connection, ok := connections[namedef.GetName().Value]; ok == true {
+			def = connection
+		}
+
 		nodes.Definition = append(nodes.Definition, def)
 
-		if gen.LangConf.IsRoot(namedef.GetName().Value) {
+		if gen.IsRoot(namedef.GetName().Value) {
 			nodes.Root = append(nodes.Root, def)
 		}
 
+		switch def.(type) {
+		case *ast.InterfaceDefinition:
+			nodes.Interface = append(nodes.Interface, def)
+		case *ast.ScalarDefinition:
+			nodes.Scalar = append(nodes.Scalar, def)
+		}
+
 		objectDef, ok := def.(*ast.ObjectDefinition)
 		if ok == false {
 			continue
@@ -326,28 +432,6 @@ func (gen *Generator) Generate() {
 
 		nodes.Object = append(nodes.Object, def)
 
-		// Find and add relay connections
-		for _, connection := range objectDef.Fields {
-			conloc := connection.Type.GetLoc()
-			contype := string(conloc.Source.Body[conloc.Start:conloc.End])
-			if strings.HasSuffix(contype, "Connection") {
-				// if _, ok := nodes.Connection[contype]; ok == true {
-				if _, ok := connections[contype]; ok == true {
-					continue
-				}
-				con := ConnectionDefinition{
-					Name: ast.NewName(&ast.Name{
-						Value: contype,
-						Loc:   conloc,
-					}),
-					Loc:      conloc,
-					NodeType: NodeByName(gen.Ast.Definitions, strings.TrimSuffix(contype, "Connection")),
-				}
-				nodes.Definition = append(nodes.Definition, con)
-				connections[contype] = true
-			}
-		}
-
 		for _, iface := range objectDef.Interfaces {
 			body := string(iface.Loc.Source.Body)
 			name := body[iface.Loc.Start:iface.Loc.End]
@@ -368,22 +452,27 @@ func (gen *Generator) Generate() {
 			select {
 			case number := <-counter:
 				sum += number
-			case <-quit:
-				fmt.Println("Generated", sum, "lines of code")
+			case success := <-quit:
+				if success == true {
+					fmt.Println("Generated", sum, "lines of code")
+				}
 				return
 			}
 		}
 	}(quit, linecounter)
 
-	for _, mainTmpl := range mainTemplates {
+	errs := make([]error, len(mainTemplates))
+
+	for i, mainTmpl := range mainTemplates {
 		wait.Add(1)
 
-		go func(mainTmpl string, counter chan int) {
+		go func(i int, mainTmpl string, counter chan int) {
 			defer wait.Done()
 
 			localTemplate, err := tmpl.Clone()
 			if err != nil {
-				panic(err)
+				errs[i] = &TemplateError{Template: mainTmpl, Err: err}
+				return
 			}
 
 			codebuffer := &utils.SwapBuffer{}
@@ -395,12 +484,17 @@ func (gen *Generator) Generate() {
 				BufferStack:   &utils.Lifo{},
 				SwapBuffer:    codebuffer,
 				LocalTemplate: localTemplate,
+				Formatter:     gen.LangConf.Formatter,
 			}
 
-			partialfunc := func(name string, data interface{}) string {
+			partialfunc := func(name string, data interface{}) (string, error) {
+				// Not every kind of definition has a template for each pass
+				if localTemplate.Lookup(name) == nil {
+					return "", nil
+				}
 				localbuffer := bytes.Buffer{}
-				localTemplate.ExecuteTemplate(&localbuffer, name, data)
-				return localbuffer.String()
+				err := localTemplate.ExecuteTemplate(&localbuffer, name, data)
+				return localbuffer.String(), err
 			}
 
 			fileFuncsMap := template.FuncMap{
@@ -413,24 +507,35 @@ func (gen *Generator) Generate() {
 
 			err = localTemplate.ExecuteTemplate(codebuffer, mainTmpl, nil)
 			if err != nil {
-				panic(err)
+				errs[i] = templateError(mainTmpl, err)
+				return
 			}
 
 			counter <- localFileFuncs.LineNumbers()
 
-		}(mainTmpl, linecounter)
+		}(i, mainTmpl, linecounter)
 	}
 
 	wait.Wait()
 
-	quit <- true
+	for _, err := range errs {
+		if err != nil {
+			quit <- false
+			return err
+		}
+	}
 
-	// fmt.Printf("Generated %d lines of code\n", lines)
+	quit <- true
 
+	return nil
 }
 
-func check(e error) {
-	if e != nil {
-		panic(e)
+// templateError unwraps errors caused by the schema from the template
+// execution error, any other error is returned as a TemplateError
+func templateError(name string, err error) error {
+	var unknownType *UnknownTypeError
+	if errors.As(err, &unknownType) {
+		return unknownType
 	}
+	return &TemplateError{Template: name, Err: err}
 }
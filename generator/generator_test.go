@@ -0,0 +1,60 @@
+package generator
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// modulePath is the import path of the granate module, the generated code
+// is written below the generator package so it's built as part of it
+const modulePath = "github.com/granateio/granate"
+
+func TestGenerateCompiles(t *testing.T) {
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("the go command is not available")
+	}
+
+	tests := []struct {
+		name    string
+		schemas []string
+	}{
+		{"example", []string{"../example/todo.graphql"}},
+		{"types", []string{"testdata/types.graphql"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir, err := os.MkdirTemp("testdata", "generated-")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			dir = filepath.ToSlash(dir)
+
+			gen, err := NewFromConfig(ProjectConfig{
+				Schemas:  test.schemas,
+				Language: "go",
+				Output: map[string]string{
+					"target":  dir + "/",
+					"package": modulePath + "/generator/" + dir,
+					"schema":  "schema",
+					"models":  "models",
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := gen.Generate(); err != nil {
+				t.Fatal(err)
+			}
+
+			output, err := exec.Command(gobin, "vet", "./"+dir+"/...").CombinedOutput()
+			if err != nil {
+				t.Fatalf("the generated code doesn't compile: %s\n%s", err, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package generator
+
+import (
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/granateio/granate/language"
+)
+
+// builtinLanguage returns the files of a built-in language pack, or nil if
+// granate has no built-in support for the language
+func builtinLanguage(name string) fs.FS {
+	files, err := fs.Sub(language.Files, name)
+	if err != nil {
+		return nil
+	}
+	if _, err := fs.Stat(files, "config.yaml"); err != nil {
+		return nil
+	}
+	return files
+}
+
+// loadLanguageConfig reads the language config, a config.yaml in dir takes
+// precedence over the built-in config
+func loadLanguageConfig(name, dir string) (LanguageConfig, error) {
+	var data []byte
+	var err error
+
+	builtin := builtinLanguage(name)
+	custom := filepath.Join(dir, "config.yaml")
+
+	switch {
+	case dir != "" && fileExistsAt(custom):
+		data, err = ioutil.ReadFile(custom)
+	case builtin != nil:
+		data, err = fs.ReadFile(builtin, "config.yaml")
+	default:
+		return LanguageConfig{}, fmt.Errorf("unsupported language '%s'", name)
+	}
+
+	if err != nil {
+		return LanguageConfig{}, err
+	}
+
+	langConfig := LanguageConfig{}
+	err = yaml.Unmarshal(data, &langConfig)
+	return langConfig, err
+}
+
+// parseLanguageTemplates parses the built-in templates followed by the
+// templates in dir, templates in dir can redefine any built-in template
+func parseLanguageTemplates(tmpl *template.Template, name, dir string) (*template.Template, error) {
+	var err error
+
+	if builtin := builtinLanguage(name); builtin != nil {
+		tmpl, err = tmpl.ParseFS(builtin, "*.tmpl")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if dir == "" {
+		return tmpl, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return tmpl, nil
+	}
+
+	return tmpl.ParseFiles(files...)
+}
+
+func fileExistsAt(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
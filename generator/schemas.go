@@ -0,0 +1,86 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/granateio/granate/generator/utils"
+)
+
+// SchemaExtensions are the file extensions used when looking for schema files
+// in a directory
+var SchemaExtensions = []string{".graphql", ".graphqls", ".gql"}
+
+// schemaFiles expands the schema entries from the project config into a list
+// of files. An entry can be a file, a directory or a glob pattern, the files
+// are returned in the order of the entries, and in lexical order for entries
+// matching multiple files
+func schemaFiles(entries []string) ([]string, error) {
+	var files []string
+	seen := make(map[string]bool)
+
+	for _, entry := range entries {
+		matches, err := utils.Glob(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schema pattern '%s': %s", entry, err)
+		}
+
+		var found []string
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil {
+				return nil, err
+			}
+
+			if info.IsDir() == false {
+				found = append(found, match)
+				continue
+			}
+
+			dirFiles, err := schemaDirFiles(match)
+			if err != nil {
+				return nil, err
+			}
+			found = append(found, dirFiles...)
+		}
+
+		if len(found) == 0 {
+			return nil, fmt.Errorf("no schema file matches '%s'", entry)
+		}
+
+		for _, file := range found {
+			if seen[file] == true {
+				continue
+			}
+			seen[file] = true
+			files = append(files, file)
+		}
+	}
+
+	return files, nil
+}
+
+// schemaDirFiles finds all schema files in dir and its sub directories
+func schemaDirFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() == true {
+			return nil
+		}
+		for _, ext := range SchemaExtensions {
+			if filepath.Ext(path) == ext {
+				files = append(files, path)
+				break
+			}
+		}
+		return nil
+	})
+
+	sort.Strings(files)
+	return files, err
+}
@@ -0,0 +1,109 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSchemaFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, file := range []string{
+		"schema.graphql",
+		"types/user.graphql",
+		"types/todo.gql",
+		"types/nested/enum.graphqls",
+		"types/README.md",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		entries []string
+		files   []string
+		err     string
+	}{
+		{
+			name:    "files in the order of the entries",
+			entries: []string{"types/user.graphql", "schema.graphql"},
+			files:   []string{"types/user.graphql", "schema.graphql"},
+		},
+		{
+			name:    "directory",
+			entries: []string{"types"},
+			files:   []string{"types/nested/enum.graphqls", "types/todo.gql", "types/user.graphql"},
+		},
+		{
+			name:    "glob",
+			entries: []string{"**/*.graphql"},
+			files:   []string{"schema.graphql", "types/user.graphql"},
+		},
+		{
+			name:    "duplicates are only included once",
+			entries: []string{"types/user.graphql", "types/*", "schema.graphql"},
+			files: []string{
+				"types/user.graphql",
+				"types/README.md",
+				"types/nested/enum.graphqls",
+				"types/todo.gql",
+				"schema.graphql",
+			},
+		},
+		{
+			name:    "no match",
+			entries: []string{"schema.graphql", "missing/*.graphql"},
+			err:     "no schema file matches",
+		},
+		{
+			name:    "missing file",
+			entries: []string{"missing.graphql"},
+			err:     "no schema file matches",
+		},
+		{
+			name:    "invalid pattern",
+			entries: []string{"["},
+			err:     "invalid schema pattern",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			entries := make([]string, len(test.entries))
+			for i, entry := range test.entries {
+				entries[i] = filepath.Join(dir, filepath.FromSlash(entry))
+			}
+
+			files, err := schemaFiles(entries)
+			if test.err != "" {
+				if err == nil || strings.Contains(err.Error(), test.err) == false {
+					t.Fatalf("error %v, expected %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var relative []string
+			for _, file := range files {
+				rel, err := filepath.Rel(dir, file)
+				if err != nil {
+					t.Fatal(err)
+				}
+				relative = append(relative, filepath.ToSlash(rel))
+			}
+			if reflect.DeepEqual(relative, test.files) == false {
+				t.Errorf("files %q, expected %q", relative, test.files)
+			}
+		})
+	}
+}
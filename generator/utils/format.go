@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// FormatGo formats go source code like gofmt, unused imports are removed so
+// templates can import every package they may need. Syntax errors are
+// reported at their position in the file with the given name
+func FormatGo(filename string, src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	used := make(map[string]bool)
+	ast.Inspect(file, func(node ast.Node) bool {
+		selector, ok := node.(*ast.SelectorExpr)
+		if ok == false {
+			return true
+		}
+		if ident, ok := selector.X.(*ast.Ident); ok == true {
+			used[ident.Name] = true
+		}
+		return true
+	})
+
+	imported := make(map[string]bool)
+	for _, spec := range append([]*ast.ImportSpec{}, file.Imports...) {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		name := ImportName(importPath)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+
+		duplicate := imported[name+" "+importPath]
+		imported[name+" "+importPath] = true
+
+		if duplicate == false && (name == "_" || name == "." || used[name] == true) {
+			continue
+		}
+
+		deleteImport(file, spec)
+	}
+
+	var output bytes.Buffer
+	err = format.Node(&output, fset, file)
+	if err != nil {
+		return nil, err
+	}
+
+	// Run the output through format.Source as well to clean up the blank
+	// lines left behind by the removed imports
+	return format.Source(output.Bytes())
+}
+
+// ImportName guesses the package name from the import path
+func ImportName(importPath string) string {
+	name := path.Base(importPath)
+
+	// Versioned packages like gopkg.in/yaml.v2 and example.com/pkg/v2
+	if strings.HasPrefix(name, "v") && strings.Trim(name[1:], "0123456789") == "" {
+		name = path.Base(path.Dir(importPath))
+	}
+	if index := strings.Index(name, ".v"); index > 0 {
+		name = name[:index]
+	}
+
+	return strings.Replace(name, "-", "_", -1)
+}
+
+func deleteImport(file *ast.File, spec *ast.ImportSpec) {
+	for i, imp := range file.Imports {
+		if imp == spec {
+			file.Imports = append(file.Imports[:i], file.Imports[i+1:]...)
+			break
+		}
+	}
+
+	for i := 0; i < len(file.Decls); i++ {
+		decl, ok := file.Decls[i].(*ast.GenDecl)
+		if ok == false || decl.Tok != token.IMPORT {
+			continue
+		}
+
+		for j, s := range decl.Specs {
+			if s == spec {
+				decl.Specs = append(decl.Specs[:j], decl.Specs[j+1:]...)
+				break
+			}
+		}
+
+		if len(decl.Specs) == 0 {
+			file.Decls = append(file.Decls[:i], file.Decls[i+1:]...)
+			i--
+		} else if len(decl.Specs) == 1 {
+			decl.Lparen = token.NoPos
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatGo(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		out  string
+		err  string
+	}{
+		{
+			name: "unused imports",
+			src: `package schema
+import (
+"context"
+"errors"
+"github.com/graphql-go/graphql"
+"github.com/graphql-go/relay"
+)
+func f(ctx context.Context) graphql.Type { return nil }
+`,
+			out: `package schema
+
+import (
+	"context"
+
+	"github.com/graphql-go/graphql"
+)
+
+func f(ctx context.Context) graphql.Type { return nil }
+`,
+		},
+		{
+			name: "no used imports",
+			src: `package schema
+import "errors"
+var x = 1
+`,
+			out: `package schema
+
+var x = 1
+`,
+		},
+		{
+			name: "named, blank and versioned imports",
+			src: `package schema
+import (
+_ "embed"
+yml "gopkg.in/yaml.v2"
+"gopkg.in/yaml.v3"
+"example.com/pkg/v2"
+"example.com/go-kit"
+"example.com/unused"
+)
+var a, b, c = yml.Marshal, pkg.X, go_kit.Y
+`,
+			out: `package schema
+
+import (
+	_ "embed"
+	yml "gopkg.in/yaml.v2"
+
+	"example.com/go-kit"
+	"example.com/pkg/v2"
+)
+
+var a, b, c = yml.Marshal, pkg.X, go_kit.Y
+`,
+		},
+		{
+			name: "duplicate imports",
+			src: `package schema
+import (
+"context"
+"context"
+)
+var ctx context.Context
+`,
+			out: `package schema
+
+import "context"
+
+var ctx context.Context
+`,
+		},
+		{
+			name: "syntax error",
+			src: `package schema
+
+func f( {
+}
+`,
+			err: "schema/definitions.go:3:9: ",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			out, err := FormatGo("schema/definitions.go", []byte(test.src))
+			if test.err != "" {
+				if err == nil || strings.HasPrefix(err.Error(), test.err) == false {
+					t.Fatalf("error %v, expected it to start with %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(out) != test.out {
+				t.Errorf("output:\n%s\nexpected:\n%s", out, test.out)
+			}
+		})
+	}
+}
+
+func TestImportName(t *testing.T) {
+	tests := map[string]string{
+		"context":                       "context",
+		"github.com/graphql-go/graphql": "graphql",
+		"gopkg.in/yaml.v2":              "yaml",
+		"example.com/pkg/v2":            "pkg",
+		"example.com/go-kit":            "go_kit",
+	}
+	for importPath, name := range tests {
+		if got := ImportName(importPath); got != name {
+			t.Errorf("ImportName(%q) = %q, expected %q", importPath, got, name)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Glob returns the names of all files matching the pattern in lexical order.
+// In addition to the filepath.Match syntax a "**" path segment matches zero
+// or more directories
+func Glob(pattern string) ([]string, error) {
+	if strings.Contains(pattern, "**") == false {
+		matches, err := filepath.Glob(pattern)
+		sort.Strings(matches)
+		return matches, err
+	}
+
+	pattern = filepath.Clean(pattern)
+	segments := strings.Split(pattern, string(filepath.Separator))
+
+	// Walk from the longest leading path without any pattern characters
+	root := ""
+	for i, segment := range segments {
+		if hasMeta(segment) {
+			root = filepath.Join(segments[:i]...)
+			if strings.HasPrefix(pattern, string(filepath.Separator)) {
+				root = string(filepath.Separator) + root
+			}
+			break
+		}
+	}
+	if root == "" {
+		root = "."
+	}
+
+	var matches []string
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		ok, err := matchSegments(segments, strings.Split(filepath.Clean(path), string(filepath.Separator)))
+		if err != nil {
+			return err
+		}
+		if ok == true {
+			matches = append(matches, path)
+		}
+		return nil
+	})
+
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	sort.Strings(matches)
+	return matches, err
+}
+
+func hasMeta(segment string) bool {
+	return strings.ContainsAny(segment, `*?[\`)
+}
+
+// matchSegments matches the path segments against the pattern segments
+func matchSegments(pattern, path []string) (bool, error) {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// Try to match the rest of the pattern at every depth
+			for i := 0; i <= len(path); i++ {
+				ok, err := matchSegments(pattern[1:], path[i:])
+				if ok == true || err != nil {
+					return ok, err
+				}
+			}
+			return false, nil
+		}
+
+		if len(path) == 0 {
+			return false, nil
+		}
+
+		ok, err := filepath.Match(pattern[0], path[0])
+		if ok == false || err != nil {
+			return false, err
+		}
+
+		pattern, path = pattern[1:], path[1:]
+	}
+
+	return len(path) == 0, nil
+}
@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// tempFiles creates the files in a temporary directory and returns the
+// directory
+func tempFiles(t *testing.T, files ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, file := range files {
+		path := filepath.Join(dir, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestGlob(t *testing.T) {
+	dir := tempFiles(t,
+		"b.graphql",
+		"a.graphql",
+		"c.txt",
+		"users/user.graphql",
+		"users/admin/admin.graphql",
+		"todos/todo.graphql",
+	)
+
+	tests := []struct {
+		pattern string
+		matches []string
+	}{
+		{"*.graphql", []string{"a.graphql", "b.graphql"}},
+		{"*", []string{"a.graphql", "b.graphql", "c.txt", "todos", "users"}},
+		{"*/*.graphql", []string{"todos/todo.graphql", "users/user.graphql"}},
+		{"**/*.graphql", []string{
+			"a.graphql",
+			"b.graphql",
+			"todos/todo.graphql",
+			"users/admin/admin.graphql",
+			"users/user.graphql",
+		}},
+		{"users/**/*.graphql", []string{"users/admin/admin.graphql", "users/user.graphql"}},
+		{"**/admin.graphql", []string{"users/admin/admin.graphql"}},
+		{"**/*.gql", nil},
+		{"missing/**/*.graphql", nil},
+		{"missing.graphql", nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.pattern, func(t *testing.T) {
+			matches, err := Glob(filepath.Join(dir, filepath.FromSlash(test.pattern)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var relative []string
+			for _, match := range matches {
+				rel, err := filepath.Rel(dir, match)
+				if err != nil {
+					t.Fatal(err)
+				}
+				relative = append(relative, filepath.ToSlash(rel))
+			}
+			if reflect.DeepEqual(relative, test.matches) == false {
+				t.Errorf("matches %q, expected %q", relative, test.matches)
+			}
+		})
+	}
+}
+
+func TestGlobInvalidPattern(t *testing.T) {
+	for _, pattern := range []string{"[", "**/["} {
+		if _, err := Glob(filepath.Join(tempFiles(t, "a.graphql"), pattern)); err == nil {
+			t.Errorf("%s: expected an error", pattern)
+		}
+	}
+}
@@ -0,0 +1,374 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// Diagnostic is a problem found in the schema
+type Diagnostic struct {
+	Position
+	Message string
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s", d.Position, d.Message)
+}
+
+// ValidationError holds every problem found while validating the schema
+type ValidationError struct {
+	Diagnostics []Diagnostic
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, len(e.Diagnostics))
+	for i, diagnostic := range e.Diagnostics {
+		lines[i] = diagnostic.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+type schemaValidator struct {
+	gen         *Generator
+	types       map[string]ast.Node
+	diagnostics []Diagnostic
+}
+
+// Validate checks the schema against the SDL validation rules, every problem
+// found is reported in a ValidationError
+func (gen *Generator) Validate() error {
+	v := &schemaValidator{
+		gen:         gen,
+		types:       make(map[string]ast.Node),
+		diagnostics: append([]Diagnostic(nil), gen.diagnostics...),
+	}
+
+	v.validateTypeNames(gen.Ast.Definitions)
+
+	for _, def := range gen.Ast.Definitions {
+		switch def := def.(type) {
+		case *ast.SchemaDefinition:
+			v.validateSchema(def)
+		case *ast.ObjectDefinition:
+			v.validateFields(def.Name, def.Fields)
+			v.validateImplementations(def)
+		case *ast.InterfaceDefinition:
+			v.validateFields(def.Name, def.Fields)
+		case *ast.InputObjectDefinition:
+			v.validateInputValues(def.Name.Value+" field", def.Fields)
+		case *ast.UnionDefinition:
+			v.validateUnion(def)
+		case *ast.EnumDefinition:
+			v.validateEnum(def)
+		}
+	}
+
+	v.validateConnections(gen.Ast.Definitions)
+	v.validateBatches(gen.Ast.Definitions)
+
+	if len(v.diagnostics) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(v.diagnostics, func(i, j int) bool {
+		a, b := v.diagnostics[i].Position, v.diagnostics[j].Position
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+
+	return &ValidationError{Diagnostics: v.diagnostics}
+}
+
+func (v *schemaValidator) report(loc *ast.Location, format string, args ...interface{}) {
+	v.diagnostics = append(v.diagnostics, Diagnostic{
+		Position: locate(loc),
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *schemaValidator) validateTypeNames(definitions []ast.Node) {
+	for _, def := range definitions {
+		named, ok := def.(namedDefinition)
+		if ok == false {
+			continue
+		}
+
+		name := named.GetName()
+		if previous, ok := v.types[name.Value]; ok == true {
+			v.report(name.Loc, "type '%s' is already defined at %s", name.Value,
+				locate(previous.(namedDefinition).GetName().Loc))
+			continue
+		}
+		if _, ok := v.gen.LangConf.Language.Scalars[name.Value]; ok == true {
+			v.report(name.Loc, "type '%s' is a built-in scalar", name.Value)
+			continue
+		}
+
+		v.types[name.Value] = def
+	}
+}
+
+// lookup returns the kind of the named type, or an empty string if the type
+// is not defined
+func (v *schemaValidator) lookup(named *ast.Named) string {
+	name := named.Name.Value
+
+	if _, ok := v.gen.LangConf.Language.Scalars[name]; ok == true {
+		return "ScalarDefinition"
+	}
+	if def, ok := v.types[name]; ok == true {
+		return def.GetKind()
+	}
+
+	v.report(named.Loc, "type '%s' is not defined", name)
+	return ""
+}
+
+func namedType(t ast.Type) *ast.Named {
+	switch t := t.(type) {
+	case *ast.NonNull:
+		return namedType(t.Type)
+	case *ast.List:
+		return namedType(t.Type)
+	case *ast.Named:
+		return t
+	}
+	return nil
+}
+
+func (v *schemaValidator) validateOutputType(t ast.Type, loc *ast.Location, context string) {
+	named := namedType(t)
+	if named == nil {
+		v.report(loc, "%s has no type", context)
+		return
+	}
+	if v.lookup(named) == "InputObjectDefinition" {
+		v.report(named.Loc, "%s can't be of the input type '%s'", context,
+			named.Name.Value)
+	}
+}
+
+func (v *schemaValidator) validateInputType(t ast.Type, loc *ast.Location, context string) {
+	named := namedType(t)
+	if named == nil {
+		v.report(loc, "%s has no type", context)
+		return
+	}
+	switch v.lookup(named) {
+	case "ObjectDefinition", "InterfaceDefinition", "UnionDefinition":
+		v.report(named.Loc, "%s can't be of the output type '%s'", context,
+			named.Name.Value)
+	}
+}
+
+func (v *schemaValidator) validateFields(parent *ast.Name, fields []*ast.FieldDefinition) {
+	seen := make(map[string]bool)
+	for _, field := range fields {
+		if seen[field.Name.Value] == true {
+			v.report(field.Name.Loc, "field '%s.%s' is defined more than once",
+				parent.Value, field.Name.Value)
+		}
+		seen[field.Name.Value] = true
+
+		context := fmt.Sprintf("field '%s.%s'", parent.Value, field.Name.Value)
+		v.validateOutputType(field.Type, field.Name.Loc, context)
+		v.validateInputValues(context+" argument", field.Arguments)
+	}
+}
+
+func (v *schemaValidator) validateInputValues(context string, values []*ast.InputValueDefinition) {
+	seen := make(map[string]bool)
+	for _, value := range values {
+		if seen[value.Name.Value] == true {
+			v.report(value.Name.Loc, "%s '%s' is defined more than once", context,
+				value.Name.Value)
+		}
+		seen[value.Name.Value] = true
+
+		v.validateInputType(value.Type, value.Name.Loc, fmt.Sprintf("%s '%s'", context, value.Name.Value))
+
+		if _, err := v.gen.defaultValue(value); err != nil {
+			var valueErr *ValueError
+			if errors.As(err, &valueErr) == false {
+				v.report(value.DefaultValue.GetLoc(), "invalid default value of %s '%s': %s",
+					context, value.Name.Value, err)
+				continue
+			}
+			v.diagnostics = append(v.diagnostics, Diagnostic{
+				Position: valueErr.Position,
+				Message:  valueErr.Message,
+			})
+		}
+	}
+}
+
+func (v *schemaValidator) validateImplementations(object *ast.ObjectDefinition) {
+	for _, iface := range object.Interfaces {
+		name := iface.Name.Value
+
+		// Node is provided by relay unless it's defined in the schema
+		if _, ok := v.types[name]; ok == false && name == "Node" {
+			continue
+		}
+
+		if kind := v.lookup(iface); kind == "" {
+			continue
+		} else if kind != "InterfaceDefinition" {
+			v.report(iface.Loc, "type '%s' implements '%s' which is not an interface",
+				object.Name.Value, name)
+			continue
+		}
+
+		ifaceDef := v.types[name].(*ast.InterfaceDefinition)
+		for _, ifaceField := range ifaceDef.Fields {
+			found := false
+			for _, field := range object.Fields {
+				if field.Name.Value == ifaceField.Name.Value {
+					found = true
+					break
+				}
+			}
+			if found == false {
+				v.report(object.Name.Loc, "type '%s' is missing the field '%s' from the interface '%s'",
+					object.Name.Value, ifaceField.Name.Value, name)
+			}
+		}
+	}
+}
+
+func (v *schemaValidator) validateUnion(union *ast.UnionDefinition) {
+	seen := make(map[string]bool)
+	for _, member := range union.Types {
+		if seen[member.Name.Value] == true {
+			v.report(member.Loc, "union '%s' includes '%s' more than once",
+				union.Name.Value, member.Name.Value)
+		}
+		seen[member.Name.Value] = true
+
+		if kind := v.lookup(member); kind != "" && kind != "ObjectDefinition" {
+			v.report(member.Loc, "union '%s' can only include object types, '%s' is not an object",
+				union.Name.Value, member.Name.Value)
+		}
+	}
+}
+
+func (v *schemaValidator) validateEnum(enum *ast.EnumDefinition) {
+	seen := make(map[string]bool)
+	for _, value := range enum.Values {
+		if seen[value.Name.Value] == true {
+			v.report(value.Name.Loc, "enum value '%s.%s' is defined more than once",
+				enum.Name.Value, value.Name.Value)
+		}
+		seen[value.Name.Value] = true
+	}
+}
+
+func (v *schemaValidator) validateSchema(schema *ast.SchemaDefinition) {
+	seen := make(map[string]bool)
+	roots := make(map[string]string)
+	for _, operation := range schema.OperationTypes {
+		if seen[operation.Operation] == true {
+			v.report(operation.Loc, "the %s operation is defined more than once",
+				operation.Operation)
+		}
+		seen[operation.Operation] = true
+
+		if kind := v.lookup(operation.Type); kind != "" && kind != "ObjectDefinition" {
+			v.report(operation.Type.Loc, "the %s root type '%s' is not an object",
+				operation.Operation, operation.Type.Name.Value)
+		}
+
+		// The fields of the subscription root type are resolved to channels,
+		// so the type can't be the root of a query or mutation as well
+		name := operation.Type.Name.Value
+		if other, ok := roots[name]; ok == true && other != operation.Operation &&
+			(other == "subscription" || operation.Operation == "subscription") {
+			v.report(operation.Type.Loc, "'%s' can't be the root type of both the %s and the %s operation",
+				name, other, operation.Operation)
+		}
+		roots[name] = operation.Operation
+	}
+
+	if seen["query"] == false {
+		v.report(schema.Loc, "the schema definition has no query root type")
+	}
+}
+
+// validateConnections checks that the connection, edge and page info types
+// can be generated as structs, i.e. their fields have no arguments, and that
+// the pagination arguments of connection fields have the relay types
+func (v *schemaValidator) validateConnections(definitions []ast.Node) {
+	nodes := connectionNodes(definitions)
+
+	for _, def := range definitions {
+		var fields []*ast.FieldDefinition
+		switch def := def.(type) {
+		case *ast.ObjectDefinition:
+			fields = def.Fields
+		case *ast.InterfaceDefinition:
+			fields = def.Fields
+		}
+
+		for _, field := range fields {
+			named := namedType(field.Type)
+			if named == nil {
+				continue
+			}
+			if _, ok := nodes[named.Name.Value].(ConnectionDefinition); ok == false {
+				continue
+			}
+			for _, arg := range field.Arguments {
+				expected, ok := paginationArgumentTypes[arg.Name.Value]
+				if ok == false || arg.Type == nil {
+					continue
+				}
+				if isListType(arg.Type) || namedType(arg.Type).Name.Value != expected {
+					v.report(arg.Type.GetLoc(), "the pagination argument '%s' of %s must be of type '%s'",
+						arg.Name.Value, field.Name.Value, expected)
+				}
+			}
+		}
+	}
+
+	for _, node := range nodes {
+		var object *ast.ObjectDefinition
+		switch node := node.(type) {
+		case ConnectionDefinition:
+			object = node.ObjectDefinition
+		case EdgeDefinition:
+			object = node.ObjectDefinition
+		case PageInfoDefinition:
+			object = node.ObjectDefinition
+			for _, field := range object.Fields {
+				expected, ok := pageInfoFields[field.Name.Value]
+				if ok == false {
+					v.report(field.Name.Loc, "%s can't have the field '%s', it's the page info of a connection",
+						object.Name.Value, field.Name.Value)
+				} else if field.Type != nil && getBody(field.Type) != expected {
+					v.report(field.Type.GetLoc(), "%s.%s must be of type '%s'",
+						object.Name.Value, field.Name.Value, expected)
+				}
+			}
+		}
+
+		if len(object.Interfaces) > 0 {
+			v.report(object.Interfaces[0].Loc, "%s can't implement interfaces, it's part of a connection",
+				object.Name.Value)
+		}
+		for _, field := range object.Fields {
+			if len(field.Arguments) > 0 {
+				v.report(field.Arguments[0].Loc, "%s.%s can't have arguments, it's part of a connection",
+					object.Name.Value, field.Name.Value)
+			}
+		}
+	}
+}
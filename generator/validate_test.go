@@ -0,0 +1,234 @@
+package generator
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// validate writes the schemas to files named a.graphql, b.graphql and so on,
+// and returns the diagnostics of the validation as file:line:column: message
+func validate(t *testing.T, schemas ...string) []string {
+	t.Helper()
+	dir := t.TempDir()
+	var files []string
+	for i, schema := range schemas {
+		file := filepath.Join(dir, string(rune('a'+i))+".graphql")
+		if err := os.WriteFile(file, []byte(schema), 0644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, file)
+	}
+
+	gen, err := NewFromConfig(ProjectConfig{Schemas: files, Language: "go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = gen.Validate()
+	if err == nil {
+		return nil
+	}
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) == false {
+		t.Fatalf("expected a ValidationError, got %T: %s", err, err)
+	}
+
+	// The positions are relative to the directory, also in the messages
+	var diagnostics []string
+	for _, diagnostic := range validationErr.Diagnostics {
+		diagnostics = append(diagnostics,
+			strings.ReplaceAll(diagnostic.String(), dir+string(filepath.Separator), ""))
+	}
+	return diagnostics
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		schemas     []string
+		diagnostics []string
+	}{
+		{
+			name: "valid",
+			schemas: []string{`
+type Query {
+    user(id: ID!): User
+}
+type User {
+    id: ID!
+}`},
+		},
+		{
+			name: "undefined types",
+			schemas: []string{`
+type Query {
+    user(filter: Filter): User
+}`},
+			diagnostics: []string{
+				"a.graphql:3:18: type 'Filter' is not defined",
+				"a.graphql:3:27: type 'User' is not defined",
+			},
+		},
+		{
+			name: "duplicate type across files",
+			schemas: []string{`
+type Query {
+    id: ID
+}`, `
+type Query {
+    name: String
+}`},
+			diagnostics: []string{
+				"b.graphql:2:6: type 'Query' is already defined at a.graphql:2:6",
+			},
+		},
+		{
+			name: "input and output types",
+			schemas: []string{`
+type Query {
+    user(filter: User): Filter
+}
+type User {
+    id: ID
+}
+input Filter {
+    id: ID
+}`},
+			diagnostics: []string{
+				"a.graphql:3:18: field 'Query.user' argument 'filter' can't be of the output type 'User'",
+				"a.graphql:3:25: field 'Query.user' can't be of the input type 'Filter'",
+			},
+		},
+		{
+			name: "duplicate fields, arguments and enum values",
+			schemas: []string{`
+type Query {
+    id: ID
+    id: ID
+    todos(first: Int, first: Int): [Status]
+}
+enum Status {
+    ACTIVE
+    ACTIVE
+}`},
+			diagnostics: []string{
+				"a.graphql:4:5: field 'Query.id' is defined more than once",
+				"a.graphql:5:23: field 'Query.todos' argument 'first' is defined more than once",
+				"a.graphql:9:5: enum value 'Status.ACTIVE' is defined more than once",
+			},
+		},
+		{
+			name: "interfaces and unions",
+			schemas: []string{`
+type Query {
+    entity: Entity
+    result: Result
+}
+interface Entity {
+    id: ID!
+}
+type User implements Entity & Query {
+    name: String
+}
+union Result = User | Entity | User`},
+			diagnostics: []string{
+				"a.graphql:9:6: type 'User' is missing the field 'id' from the interface 'Entity'",
+				"a.graphql:9:31: type 'User' implements 'Query' which is not an interface",
+				"a.graphql:12:23: union 'Result' can only include object types, 'Entity' is not an object",
+				"a.graphql:12:32: union 'Result' includes 'User' more than once",
+			},
+		},
+		{
+			name: "schema definition",
+			schemas: []string{`
+schema {
+    mutation: Root
+    subscription: Root
+    mutation: Status
+}
+type Root {
+    id: ID
+}
+enum Status {
+    ACTIVE
+}`},
+			diagnostics: []string{
+				"a.graphql:2:1: the schema definition has no query root type",
+				"a.graphql:4:19: 'Root' can't be the root type of both the mutation and the subscription operation",
+				"a.graphql:5:5: the mutation operation is defined more than once",
+				"a.graphql:5:15: the mutation root type 'Status' is not an object",
+			},
+		},
+		{
+			name: "invalid default values",
+			schemas: []string{`
+type Query {
+    todos(first: Int = "ten", status: Status = DONE, after: String = 1): [ID]
+}
+enum Status {
+    ACTIVE
+}`},
+			diagnostics: []string{
+				"a.graphql:3:24: \"ten\" is not a valid Int value",
+				"a.graphql:3:48: DONE is not a valid Status value",
+				"a.graphql:3:70: 1 is not a valid String value",
+			},
+		},
+		{
+			name: "batched fields",
+			schemas: []string{`
+type Query {
+    user: User @batch
+}
+type User {
+    name: String! @batch
+    todos: [ID] @batch
+}`},
+			diagnostics: []string{
+				"a.graphql:3:5: Query.user can't be batched, Query is the query root type",
+				"a.graphql:6:5: User.name can't be batched, batched fields must be nullable",
+			},
+		},
+		{
+			name: "extensions, connections and other errors",
+			schemas: []string{`
+type Query {
+    a: Missing
+    friends: FriendConnection @connection
+}
+enum Status {
+    ACTIVE
+}`, `
+extend type Nope {
+    b: Int
+}
+extend type Status {
+    c: Int
+}
+extend type Query {
+    a: Int
+}`},
+			diagnostics: []string{
+				"a.graphql:3:8: type 'Missing' is not defined",
+				"a.graphql:4:14: type 'FriendConnection' is not defined",
+				"a.graphql:4:31: can't find the node type of the connection 'FriendConnection', set it with @connection(node: \"Type\")",
+				"b.graphql:2:13: can't extend type 'Nope', the type is not defined",
+				"b.graphql:5:13: can't extend type 'Status', only object types can be extended",
+				"b.graphql:9:5: field 'Query.a' is defined more than once",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			diagnostics := validate(t, test.schemas...)
+			if reflect.DeepEqual(diagnostics, test.diagnostics) == false {
+				t.Errorf("diagnostics:\n%q\nexpected:\n%q", diagnostics, test.diagnostics)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package generator
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// defaultValue returns the default value of an argument or input field as a
+// Go literal, or an empty string when there is no default value
+func (gen *Generator) defaultValue(def *ast.InputValueDefinition) (string, error) {
+	if def.DefaultValue == nil {
+		return "", nil
+	}
+	return gen.goLiteral(def.DefaultValue, def.Type)
+}
+
+// goLiteral converts a schema value to a Go literal of the value graphql-go
+// uses internally for the type, i.e. the value a resolver receives in its
+// arguments
+func (gen *Generator) goLiteral(value ast.Value, t ast.Type) (string, error) {
+	switch t := t.(type) {
+	case *ast.NonNull:
+		return gen.goLiteral(value, t.Type)
+	case *ast.List:
+		// A single value is accepted where a list is expected
+		values := []ast.Value{value}
+		if list, ok := value.(*ast.ListValue); ok == true {
+			values = list.Values
+		}
+
+		items := make([]string, len(values))
+		for i, item := range values {
+			literal, err := gen.goLiteral(item, t.Type)
+			if err != nil {
+				return "", err
+			}
+			items[i] = literal
+		}
+		return "[]interface{}{" + strings.Join(items, ", ") + "}", nil
+	case *ast.Named:
+		return gen.namedLiteral(value, t)
+	}
+
+	return "", valueError(value.GetLoc(), "unsupported type")
+}
+
+func (gen *Generator) namedLiteral(value ast.Value, t *ast.Named) (string, error) {
+	name := t.Name.Value
+	invalid := func() (string, error) {
+		return "", valueError(value.GetLoc(), "%s is not a valid %s value", getBody(value), name)
+	}
+
+	switch name {
+	case "Int":
+		if value, ok := value.(*ast.IntValue); ok == true {
+			return value.Value, nil
+		}
+		return invalid()
+	case "Float":
+		switch value := value.(type) {
+		case *ast.IntValue:
+			return "float64(" + value.Value + ")", nil
+		case *ast.FloatValue:
+			return "float64(" + value.Value + ")", nil
+		}
+		return invalid()
+	case "String":
+		if value, ok := value.(*ast.StringValue); ok == true {
+			return strconv.Quote(value.Value), nil
+		}
+		return invalid()
+	case "ID":
+		switch value := value.(type) {
+		case *ast.IntValue:
+			return strconv.Quote(value.Value), nil
+		case *ast.StringValue:
+			return strconv.Quote(value.Value), nil
+		}
+		return invalid()
+	case "Boolean":
+		if value, ok := value.(*ast.BooleanValue); ok == true {
+			return strconv.FormatBool(value.Value), nil
+		}
+		return invalid()
+	}
+
+	switch def := NodeByName(gen.Ast.Definitions, name).(type) {
+	case *ast.EnumDefinition:
+		enumValue, ok := value.(*ast.EnumValue)
+		if ok == false {
+			return invalid()
+		}
+		for _, v := range def.Values {
+			if v.Name.Value == enumValue.Value {
+				return name + camel(enumValue.Value), nil
+			}
+		}
+		return invalid()
+	case *ast.InputObjectDefinition:
+		object, ok := value.(*ast.ObjectValue)
+		if ok == false {
+			return invalid()
+		}
+		var fields []string
+		for _, field := range object.Fields {
+			var fieldDef *ast.InputValueDefinition
+			for _, f := range def.Fields {
+				if f.Name.Value == field.Name.Value {
+					fieldDef = f
+				}
+			}
+			if fieldDef == nil {
+				return "", valueError(field.Loc, "%s has no field '%s'", name, field.Name.Value)
+			}
+			literal, err := gen.goLiteral(field.Value, fieldDef.Type)
+			if err != nil {
+				return "", err
+			}
+			fields = append(fields, strconv.Quote(field.Name.Value)+": "+literal)
+		}
+
+		// graphql-go uses the default value as is, so the defaults of the
+		// missing fields are added here
+		for _, fieldDef := range def.Fields {
+			if fieldDef.DefaultValue == nil || objectField(object, fieldDef.Name.Value) != nil {
+				continue
+			}
+			literal, err := gen.defaultValue(fieldDef)
+			if err != nil {
+				return "", err
+			}
+			fields = append(fields, strconv.Quote(fieldDef.Name.Value)+": "+literal)
+		}
+		return "map[string]interface{}{" + strings.Join(fields, ", ") + "}", nil
+	case *ast.ScalarDefinition:
+		// The internal value of a custom scalar is only known to its
+		// ParseLiteral implementation, which isn't available until the
+		// provider is initialized
+		if _, mapped := gen.Config.Scalars[name]; mapped == true {
+			return "", valueError(value.GetLoc(),
+				"default values of the mapped scalar %s are not supported", name)
+		}
+		return gen.rawLiteral(value)
+	}
+
+	return "", valueError(value.GetLoc(), "type '%s' can't have a default value", name)
+}
+
+func objectField(object *ast.ObjectValue, name string) *ast.ObjectField {
+	for _, field := range object.Fields {
+		if field.Name.Value == name {
+			return field
+		}
+	}
+	return nil
+}
+
+// rawLiteral converts a value to a Go literal without a type to guide the
+// conversion
+func (gen *Generator) rawLiteral(value ast.Value) (string, error) {
+	switch value := value.(type) {
+	case *ast.IntValue:
+		return value.Value, nil
+	case *ast.FloatValue:
+		return "float64(" + value.Value + ")", nil
+	case *ast.StringValue:
+		return strconv.Quote(value.Value), nil
+	case *ast.EnumValue:
+		return strconv.Quote(value.Value), nil
+	case *ast.BooleanValue:
+		return strconv.FormatBool(value.Value), nil
+	case *ast.ListValue:
+		items := make([]string, len(value.Values))
+		for i, item := range value.Values {
+			literal, err := gen.rawLiteral(item)
+			if err != nil {
+				return "", err
+			}
+			items[i] = literal
+		}
+		return "[]interface{}{" + strings.Join(items, ", ") + "}", nil
+	case *ast.ObjectValue:
+		fields := make([]string, len(value.Fields))
+		for i, field := range value.Fields {
+			literal, err := gen.rawLiteral(field.Value)
+			if err != nil {
+				return "", err
+			}
+			fields[i] = strconv.Quote(field.Name.Value) + ": " + literal
+		}
+		return "map[string]interface{}{" + strings.Join(fields, ", ") + "}", nil
+	}
+
+	return "", valueError(value.GetLoc(), "%s can't be used as a default value", getBody(value))
+}
@@ -0,0 +1,10 @@
+// Package language contains the built-in language packs, each language has a
+// config.yaml and a set of templates in a directory named after the language
+package language
+
+import "embed"
+
+// Files holds the built-in language packs
+//
+//go:embed go
+var Files embed.FS
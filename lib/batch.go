@@ -0,0 +1,176 @@
+package lib
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// BatchFunc loads a field for a batch of sources, it returns a value for
+// every source and either no errors or an error for every source
+type BatchFunc func(ctx context.Context, sources []interface{}) ([]interface{}, []error)
+
+// BatchError is returned for every source of a batch when the batch function
+// doesn't return a value, or an error, for every source
+type BatchError struct {
+	Field   string
+	Sources int
+	Values  int
+	Errors  int
+}
+
+func (e *BatchError) Error() string {
+	if e.Values != e.Sources {
+		return fmt.Sprintf("the batch of %s returned %d values for %d sources", e.Field, e.Values, e.Sources)
+	}
+	return fmt.Sprintf("the batch of %s returned %d errors for %d sources", e.Field, e.Errors, e.Sources)
+}
+
+type loadersKey struct{}
+
+// loaders holds the loaders of a request, one for every batched field and
+// set of arguments
+type loaders struct {
+	mu      sync.Mutex
+	loaders map[string]*loader
+}
+
+// WithLoaders returns a context with an empty set of loaders, batched fields
+// resolved with the context are loaded together and cached for the lifetime
+// of the context. The handler returned by NewHandler adds loaders to every
+// request, a context without loaders loads every source on its own
+func WithLoaders(ctx context.Context) context.Context {
+	return context.WithValue(ctx, loadersKey{}, &loaders{loaders: make(map[string]*loader)})
+}
+
+// LoadBatch adds the source to the pending batch of the field and returns a
+// thunk for graphql-go that resolves the value of the source. The batch is
+// loaded when the first of its thunks is called, which graphql-go does after
+// it resolved all the fields at the same depth of the query, so the sources
+// of e.g. every item in a list end up in the same batch. Fields with
+// different arguments are batched separately
+func LoadBatch(ctx context.Context, field string, args map[string]interface{}, source interface{}, batch BatchFunc) func() (interface{}, error) {
+	set, ok := ctx.Value(loadersKey{}).(*loaders)
+	if ok == false {
+		return (&loader{field: field, batch: batch, ctx: ctx}).load(source)
+	}
+
+	key := field
+	if len(args) > 0 {
+		// Maps are encoded with sorted keys, so equal arguments give
+		// equal keys
+		encoded, _ := json.Marshal(args)
+		key += string(encoded)
+	}
+
+	set.mu.Lock()
+	l, ok := set.loaders[key]
+	if ok == false {
+		l = &loader{
+			field: field,
+			batch: batch,
+			ctx:   ctx,
+			cache: make(map[interface{}]*loaderCall),
+		}
+		set.loaders[key] = l
+	}
+	set.mu.Unlock()
+
+	return l.load(source)
+}
+
+// loader collects the sources of a field into batches, sources that are
+// comparable are only loaded once when the loader has a cache
+type loader struct {
+	field string
+	batch BatchFunc
+	ctx   context.Context
+
+	mu      sync.Mutex
+	pending *loaderBatch
+	cache   map[interface{}]*loaderCall
+}
+
+type loaderBatch struct {
+	once  sync.Once
+	calls []*loaderCall
+}
+
+type loaderCall struct {
+	batch  *loaderBatch
+	source interface{}
+	value  interface{}
+	err    error
+}
+
+func (l *loader) load(source interface{}) func() (interface{}, error) {
+	l.mu.Lock()
+	cacheable := l.cache != nil && source != nil && reflect.ValueOf(source).Comparable()
+	var call *loaderCall
+	if cacheable == true {
+		call = l.cache[source]
+	}
+	if call == nil {
+		if l.pending == nil {
+			l.pending = &loaderBatch{}
+		}
+		call = &loaderCall{batch: l.pending, source: source}
+		l.pending.calls = append(l.pending.calls, call)
+		if cacheable == true {
+			l.cache[source] = call
+		}
+	}
+	l.mu.Unlock()
+
+	return func() (interface{}, error) {
+		call.batch.once.Do(func() {
+			l.dispatch(call.batch)
+		})
+		return call.value, call.err
+	}
+}
+
+// dispatch loads a batch, the loader starts a new batch for the sources that
+// are added from now on
+func (l *loader) dispatch(batch *loaderBatch) {
+	l.mu.Lock()
+	if l.pending == batch {
+		l.pending = nil
+	}
+	l.mu.Unlock()
+
+	sources := make([]interface{}, len(batch.calls))
+	for i, call := range batch.calls {
+		sources[i] = call.source
+	}
+
+	values, errs := l.call(sources)
+	for i, call := range batch.calls {
+		switch {
+		case len(values) != len(sources) || (errs != nil && len(errs) != len(sources)):
+			call.err = &BatchError{Field: l.field, Sources: len(sources), Values: len(values), Errors: len(errs)}
+		case errs != nil && errs[i] != nil:
+			call.err = errs[i]
+		default:
+			call.value = values[i]
+		}
+	}
+}
+
+// call runs the batch function, a panic fails every source of the batch
+// instead of only the field that happened to load it
+func (l *loader) call(sources []interface{}) (values []interface{}, errs []error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err := fmt.Errorf("the batch of %s failed: %v", l.field, r)
+			values = make([]interface{}, len(sources))
+			errs = make([]error, len(sources))
+			for i := range errs {
+				errs[i] = err
+			}
+		}
+	}()
+	return l.batch(l.ctx, sources)
+}
@@ -0,0 +1,193 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// recorder is a batch function that records the sources of every batch
+type recorder struct {
+	batches [][]interface{}
+	load    BatchFunc
+}
+
+func (r *recorder) batch(ctx context.Context, sources []interface{}) ([]interface{}, []error) {
+	r.batches = append(r.batches, sources)
+	return r.load(ctx, sources)
+}
+
+func double(ctx context.Context, sources []interface{}) ([]interface{}, []error) {
+	values := make([]interface{}, len(sources))
+	for i, source := range sources {
+		values[i] = source.(int) * 2
+	}
+	return values, nil
+}
+
+func TestLoadBatch(t *testing.T) {
+	type result struct {
+		value interface{}
+		err   string
+	}
+
+	tests := []struct {
+		name    string
+		sources []interface{}
+		load    BatchFunc
+		batches [][]interface{}
+		results []result
+	}{
+		{
+			name:    "batch",
+			sources: []interface{}{1, 2, 3},
+			load:    double,
+			batches: [][]interface{}{{1, 2, 3}},
+			results: []result{{2, ""}, {4, ""}, {6, ""}},
+		},
+		{
+			name:    "same source",
+			sources: []interface{}{1, 2, 1},
+			load:    double,
+			batches: [][]interface{}{{1, 2}},
+			results: []result{{2, ""}, {4, ""}, {2, ""}},
+		},
+		{
+			name:    "error per source",
+			sources: []interface{}{1, 2},
+			load: func(ctx context.Context, sources []interface{}) ([]interface{}, []error) {
+				return []interface{}{"one", nil}, []error{nil, errors.New("not found")}
+			},
+			batches: [][]interface{}{{1, 2}},
+			results: []result{{"one", ""}, {nil, "not found"}},
+		},
+		{
+			name:    "missing values",
+			sources: []interface{}{1, 2},
+			load: func(ctx context.Context, sources []interface{}) ([]interface{}, []error) {
+				return []interface{}{"one"}, nil
+			},
+			batches: [][]interface{}{{1, 2}},
+			results: []result{
+				{nil, "the batch of User.todos returned 1 values for 2 sources"},
+				{nil, "the batch of User.todos returned 1 values for 2 sources"},
+			},
+		},
+		{
+			name:    "missing errors",
+			sources: []interface{}{1, 2},
+			load: func(ctx context.Context, sources []interface{}) ([]interface{}, []error) {
+				return []interface{}{"one", "two"}, []error{errors.New("failed")}
+			},
+			batches: [][]interface{}{{1, 2}},
+			results: []result{
+				{nil, "the batch of User.todos returned 1 errors for 2 sources"},
+				{nil, "the batch of User.todos returned 1 errors for 2 sources"},
+			},
+		},
+		{
+			name:    "panic",
+			sources: []interface{}{1, 2},
+			load: func(ctx context.Context, sources []interface{}) ([]interface{}, []error) {
+				panic("boom")
+			},
+			batches: [][]interface{}{{1, 2}},
+			results: []result{
+				{nil, "the batch of User.todos failed: boom"},
+				{nil, "the batch of User.todos failed: boom"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := &recorder{load: test.load}
+			ctx := WithLoaders(context.Background())
+
+			thunks := make([]func() (interface{}, error), len(test.sources))
+			for i, source := range test.sources {
+				thunks[i] = LoadBatch(ctx, "User.todos", nil, source, r.batch)
+			}
+			if len(r.batches) != 0 {
+				t.Fatal("the batch was loaded before a thunk was called")
+			}
+
+			for i, thunk := range thunks {
+				value, err := thunk()
+				got := result{value: value}
+				if err != nil {
+					got.err = err.Error()
+				}
+				if got != test.results[i] {
+					t.Errorf("source %d resolved to %v, expected %v", i, got, test.results[i])
+				}
+			}
+			if reflect.DeepEqual(r.batches, test.batches) == false {
+				t.Errorf("batches %v, expected %v", r.batches, test.batches)
+			}
+		})
+	}
+}
+
+func TestLoadBatchArguments(t *testing.T) {
+	r := &recorder{load: double}
+	ctx := WithLoaders(context.Background())
+
+	first := LoadBatch(ctx, "User.todos", map[string]interface{}{"first": 1, "status": "DONE"}, 1, r.batch)
+	same := LoadBatch(ctx, "User.todos", map[string]interface{}{"status": "DONE", "first": 1}, 2, r.batch)
+	other := LoadBatch(ctx, "User.todos", map[string]interface{}{"first": 2}, 3, r.batch)
+	for _, thunk := range []func() (interface{}, error){first, same, other} {
+		if _, err := thunk(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := [][]interface{}{{1, 2}, {3}}
+	if reflect.DeepEqual(r.batches, expected) == false {
+		t.Errorf("batches %v, expected %v", r.batches, expected)
+	}
+}
+
+func TestLoadBatchCache(t *testing.T) {
+	r := &recorder{load: double}
+	ctx := WithLoaders(context.Background())
+
+	if _, err := LoadBatch(ctx, "User.todos", nil, 1, r.batch)(); err != nil {
+		t.Fatal(err)
+	}
+	// The first batch is loaded, so new sources start a new batch and
+	// loaded sources come from the cache
+	cached := LoadBatch(ctx, "User.todos", nil, 1, r.batch)
+	next := LoadBatch(ctx, "User.todos", nil, 2, r.batch)
+	if value, _ := cached(); value != 2 {
+		t.Errorf("cached value %v, expected 2", value)
+	}
+	if value, _ := next(); value != 4 {
+		t.Errorf("value %v, expected 4", value)
+	}
+
+	expected := [][]interface{}{{1}, {2}}
+	if reflect.DeepEqual(r.batches, expected) == false {
+		t.Errorf("batches %v, expected %v", r.batches, expected)
+	}
+}
+
+func TestLoadBatchWithoutLoaders(t *testing.T) {
+	r := &recorder{load: double}
+	ctx := context.Background()
+
+	one := LoadBatch(ctx, "User.todos", nil, 1, r.batch)
+	two := LoadBatch(ctx, "User.todos", nil, 1, r.batch)
+	if value, _ := one(); value != 2 {
+		t.Errorf("value %v, expected 2", value)
+	}
+	if value, _ := two(); value != 2 {
+		t.Errorf("value %v, expected 2", value)
+	}
+
+	expected := [][]interface{}{{1}, {1}}
+	if reflect.DeepEqual(r.batches, expected) == false {
+		t.Errorf("batches %v, expected %v", r.batches, expected)
+	}
+}
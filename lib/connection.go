@@ -0,0 +1,9 @@
+package lib
+
+// PageInfo is the native type of the relay PageInfo object of a connection
+type PageInfo struct {
+	HasNextPage     bool
+	HasPreviousPage bool
+	StartCursor     *string
+	EndCursor       *string
+}
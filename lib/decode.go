@@ -0,0 +1,151 @@
+package lib
+
+import (
+	"fmt"
+)
+
+// ArgumentError is returned when an argument can't be decoded, the path points
+// at the invalid value, e.g. filter.tags[2]
+type ArgumentError struct {
+	Path    string
+	Message string
+}
+
+func (e *ArgumentError) Error() string {
+	return fmt.Sprintf("argument %s: %s", e.Path, e.Message)
+}
+
+// NewArgumentError returns an ArgumentError for a value that is not of the
+// expected type
+func NewArgumentError(path string, expected string, value interface{}) error {
+	return &ArgumentError{
+		Path:    path,
+		Message: fmt.Sprintf("expected %s, got %T", expected, value),
+	}
+}
+
+// FieldPath returns the path of a field in an input object
+func FieldPath(path string, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+// DecodeString decodes a String argument
+func DecodeString(value interface{}, path string) (string, error) {
+	switch value := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return value, nil
+	case *string:
+		if value != nil {
+			return *value, nil
+		}
+		return "", nil
+	}
+	return "", NewArgumentError(path, "String", value)
+}
+
+// DecodeID decodes an ID argument
+func DecodeID(value interface{}, path string) (string, error) {
+	switch value := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return value, nil
+	case int:
+		return fmt.Sprint(value), nil
+	}
+	return "", NewArgumentError(path, "ID", value)
+}
+
+// DecodeInt decodes an Int argument
+func DecodeInt(value interface{}, path string) (int, error) {
+	switch value := value.(type) {
+	case nil:
+		return 0, nil
+	case int:
+		return value, nil
+	case int32:
+		return int(value), nil
+	case int64:
+		return int(value), nil
+	}
+	return 0, NewArgumentError(path, "Int", value)
+}
+
+// DecodeFloat decodes a Float argument
+func DecodeFloat(value interface{}, path string) (float64, error) {
+	switch value := value.(type) {
+	case nil:
+		return 0, nil
+	case float64:
+		return value, nil
+	case float32:
+		return float64(value), nil
+	case int:
+		return float64(value), nil
+	}
+	return 0, NewArgumentError(path, "Float", value)
+}
+
+// DecodeBoolean decodes a Boolean argument
+func DecodeBoolean(value interface{}, path string) (bool, error) {
+	switch value := value.(type) {
+	case nil:
+		return false, nil
+	case bool:
+		return value, nil
+	}
+	return false, NewArgumentError(path, "Boolean", value)
+}
+
+// DecodeValue decodes an argument of a custom scalar without a native type,
+// the value is passed on as parsed by the scalar
+func DecodeValue(value interface{}, path string) (interface{}, error) {
+	return value, nil
+}
+
+// DecodeList calls decode for every item of a list argument with the path of
+// the item
+func DecodeList(value interface{}, path string, decode func(item interface{}, path string) error) error {
+	if value == nil {
+		return nil
+	}
+
+	items, ok := value.([]interface{})
+	if ok == false {
+		return NewArgumentError(path, "a list", value)
+	}
+
+	for i, item := range items {
+		if err := decode(item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DecodeObject decodes an input object argument to its fields, a nil map is
+// returned when the value is not set
+func DecodeObject(value interface{}, path string) (map[string]interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	fields, ok := value.(map[string]interface{})
+	if ok == false {
+		return nil, NewArgumentError(path, "an input object", value)
+	}
+	return fields, nil
+}
+
+// DecodeClientMutationID returns the clientMutationId of a relay mutation
+// input
+func DecodeClientMutationID(args map[string]interface{}) string {
+	input, _ := args["input"].(map[string]interface{})
+	id, _ := input["clientMutationId"].(string)
+	return id
+}
@@ -0,0 +1,132 @@
+package lib
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeScalars(t *testing.T) {
+	text := "text"
+	tests := []struct {
+		name   string
+		decode func(interface{}, string) (interface{}, error)
+		value  interface{}
+		result interface{}
+		err    string
+	}{
+		{"string", decodeAny(DecodeString), "text", "text", ""},
+		{"string pointer", decodeAny(DecodeString), &text, "text", ""},
+		{"string nil", decodeAny(DecodeString), nil, "", ""},
+		{"string from int", decodeAny(DecodeString), 1, "", "argument arg: expected String, got int"},
+		{"id string", decodeAny(DecodeID), "1", "1", ""},
+		{"id int", decodeAny(DecodeID), 1, "1", ""},
+		{"id from bool", decodeAny(DecodeID), true, "", "argument arg: expected ID, got bool"},
+		{"int", decodeAny(DecodeInt), 1, 1, ""},
+		{"int64", decodeAny(DecodeInt), int64(1), 1, ""},
+		{"int from float", decodeAny(DecodeInt), 1.5, 0, "argument arg: expected Int, got float64"},
+		{"int from string", decodeAny(DecodeInt), "1", 0, "argument arg: expected Int, got string"},
+		{"float", decodeAny(DecodeFloat), 1.5, 1.5, ""},
+		{"float from int", decodeAny(DecodeFloat), 1, 1.0, ""},
+		{"float from string", decodeAny(DecodeFloat), "1.5", 0.0, "argument arg: expected Float, got string"},
+		{"boolean", decodeAny(DecodeBoolean), true, true, ""},
+		{"boolean from int", decodeAny(DecodeBoolean), 1, false, "argument arg: expected Boolean, got int"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := test.decode(test.value, "arg")
+			if test.err != "" {
+				if err == nil || err.Error() != test.err {
+					t.Fatalf("error %v, expected %q", err, test.err)
+				}
+				var argErr *ArgumentError
+				if errors.As(err, &argErr) == false || argErr.Path != "arg" {
+					t.Errorf("expected an ArgumentError for arg, got %#v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result != test.result {
+				t.Errorf("result %#v, expected %#v", result, test.result)
+			}
+		})
+	}
+}
+
+// decodeAny converts a typed decode function so they fit in one table
+func decodeAny[T any](decode func(interface{}, string) (T, error)) func(interface{}, string) (interface{}, error) {
+	return func(value interface{}, path string) (interface{}, error) {
+		return decode(value, path)
+	}
+}
+
+func TestDecodeList(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  interface{}
+		result []int
+		err    string
+	}{
+		{"nil", nil, nil, ""},
+		{"items", []interface{}{1, 2}, []int{1, 2}, ""},
+		{"not a list", 1, nil, "argument ids: expected a list, got int"},
+		{"invalid item", []interface{}{1, "2"}, []int{1}, "argument ids[1]: expected Int, got string"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var result []int
+			err := DecodeList(test.value, "ids", func(item interface{}, path string) error {
+				decoded, err := DecodeInt(item, path)
+				if err == nil {
+					result = append(result, decoded)
+				}
+				return err
+			})
+			if test.err != "" && (err == nil || err.Error() != test.err) {
+				t.Fatalf("error %v, expected %q", err, test.err)
+			}
+			if test.err == "" && err != nil {
+				t.Fatal(err)
+			}
+			if reflect.DeepEqual(result, test.result) == false {
+				t.Errorf("result %v, expected %v", result, test.result)
+			}
+		})
+	}
+}
+
+func TestDecodeObject(t *testing.T) {
+	fields, err := DecodeObject(map[string]interface{}{"id": 1}, "filter")
+	if err != nil || fields["id"] != 1 {
+		t.Errorf("fields %v, error %v", fields, err)
+	}
+
+	fields, err = DecodeObject(nil, "filter")
+	if err != nil || fields != nil {
+		t.Errorf("expected no fields and no error for nil, got %v, %v", fields, err)
+	}
+
+	_, err = DecodeObject([]interface{}{}, "filter")
+	if err == nil || err.Error() != "argument filter: expected an input object, got []interface {}" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestFieldPath(t *testing.T) {
+	tests := []struct {
+		path, field, result string
+	}{
+		{"", "filter", "filter"},
+		{"filter", "tags", "filter.tags"},
+		{"filter.tags[2]", "name", "filter.tags[2].name"},
+	}
+	for _, test := range tests {
+		if result := FieldPath(test.path, test.field); result != test.result {
+			t.Errorf("FieldPath(%q, %q) = %q, expected %q", test.path, test.field, result, test.result)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package lib
+
+import (
+	"embed"
+	"encoding/json"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"strings"
+)
+
+// The schema explorer and its assets are embedded, so it works without access
+// to a CDN. It's a small query editor with a documentation browser
+//
+//go:embed explorer
+var explorerFiles embed.FS
+
+var explorerTemplate = template.Must(template.ParseFS(explorerFiles, "explorer/index.html"))
+
+// ExplorerOptions configure the schema explorer
+type ExplorerOptions struct {
+	// Path the explorer is served at, the assets are served below
+	// ExplorerAssetsPath(Path)
+	Path string
+
+	// Endpoint is the path of the graphql endpoint
+	Endpoint string
+
+	// Headers are the initial request headers, they can be changed in the
+	// explorer and are sent with every request, e.g. an Authorization header
+	Headers map[string]string
+}
+
+// ExplorerAssetsPath returns the path the assets of a schema explorer served
+// at path are served at
+func ExplorerAssetsPath(path string) string {
+	return strings.TrimSuffix(path, "/") + "/explorer/"
+}
+
+// ExplorerHandler serves the schema explorer at opts.Path and its assets
+// below ExplorerAssetsPath(opts.Path), the handler has to be mounted at both
+func ExplorerHandler(opts ExplorerOptions) http.Handler {
+	if opts.Path == "" {
+		opts.Path = "/"
+	}
+
+	headers := []byte("{}")
+	if len(opts.Headers) > 0 {
+		headers, _ = json.MarshalIndent(opts.Headers, "", "  ")
+	}
+
+	assetsPath := ExplorerAssetsPath(opts.Path)
+	assets, _ := fs.Sub(explorerFiles, "explorer")
+	files := http.StripPrefix(assetsPath, http.FileServer(http.FS(assets)))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == opts.Path:
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			explorerTemplate.Execute(w, struct {
+				Endpoint string
+				Assets   string
+				Headers  string
+			}{opts.Endpoint, assetsPath, string(headers)})
+		case strings.HasPrefix(r.URL.Path, assetsPath) && isExplorerAsset(strings.TrimPrefix(r.URL.Path, assetsPath)):
+			files.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// isExplorerAsset reports whether name is one of the scripts or stylesheets,
+// the page template and directory listings are not served
+func isExplorerAsset(name string) bool {
+	return strings.HasSuffix(name, ".js") || strings.HasSuffix(name, ".css")
+}
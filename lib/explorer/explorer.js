@@ -0,0 +1,395 @@
+// A small schema explorer without any external dependencies, so it works
+// without access to a CDN. The query, variables and headers are kept in the
+// local storage of the browser, the headers are sent with every request.
+(function () {
+   "use strict";
+
+   var root = document.getElementById("explorer");
+   var endpoint = root.getAttribute("data-endpoint");
+   var storagePrefix = "explorer:" + endpoint + ":";
+
+   var queryEditor = document.getElementById("query");
+   var variablesEditor = document.getElementById("variables");
+   var headersEditor = document.getElementById("headers");
+   var operationSelect = document.getElementById("operation");
+   var resultView = document.getElementById("result");
+   var docs = document.getElementById("docs");
+   var docsPath = document.getElementById("docs-path");
+   var docsContent = document.getElementById("docs-content");
+
+   // The introspected schema of the documentation, it is loaded again when
+   // the headers change
+   var schema = null;
+   var docsHistory = [];
+
+   var introspectionQuery = "query IntrospectionQuery {\n" +
+      "  __schema {\n" +
+      "    queryType { name }\n" +
+      "    mutationType { name }\n" +
+      "    subscriptionType { name }\n" +
+      "    types {\n" +
+      "      kind name description\n" +
+      "      fields(includeDeprecated: true) {\n" +
+      "        name description isDeprecated deprecationReason\n" +
+      "        args { name description defaultValue type { ...TypeRef } }\n" +
+      "        type { ...TypeRef }\n" +
+      "      }\n" +
+      "      inputFields { name description defaultValue type { ...TypeRef } }\n" +
+      "      enumValues(includeDeprecated: true) { name description isDeprecated deprecationReason }\n" +
+      "      interfaces { name }\n" +
+      "      possibleTypes { name }\n" +
+      "    }\n" +
+      "  }\n" +
+      "}\n" +
+      "fragment TypeRef on __Type {\n" +
+      "  kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name } } } }\n" +
+      "}\n";
+
+   function load(name, fallback) {
+      try {
+         var value = window.localStorage.getItem(storagePrefix + name);
+         return value === null ? fallback : value;
+      } catch (error) {
+         return fallback;
+      }
+   }
+
+   function save(name, value) {
+      try {
+         window.localStorage.setItem(storagePrefix + name, value);
+      } catch (error) {
+         // The storage is not available, e.g. in a private window
+      }
+   }
+
+   function parseObject(text, name) {
+      if (text.trim() === "") {
+         return {};
+      }
+      var value;
+      try {
+         value = JSON.parse(text);
+      } catch (error) {
+         throw new Error("The " + name + " are not valid JSON: " + error.message);
+      }
+      if (value === null || typeof value !== "object" || Array.isArray(value)) {
+         throw new Error("The " + name + " must be a JSON object");
+      }
+      return value;
+   }
+
+   // execute sends a graphql request with the headers from the headers
+   // editor and returns the parsed response
+   function execute(params) {
+      var headers = {
+         "Accept": "application/json",
+         "Content-Type": "application/json"
+      };
+      var custom = parseObject(headersEditor.value, "headers");
+      Object.keys(custom).forEach(function (name) {
+         headers[name] = String(custom[name]);
+      });
+
+      return fetch(endpoint, {
+         method: "POST",
+         headers: headers,
+         body: JSON.stringify(params),
+         credentials: "include"
+      }).then(function (response) {
+         return response.text();
+      }).then(function (body) {
+         try {
+            return JSON.parse(body);
+         } catch (error) {
+            return body;
+         }
+      });
+   }
+
+   function showResult(result) {
+      resultView.textContent = typeof result === "string" ? result : JSON.stringify(result, null, 2);
+   }
+
+   function operationNames(query) {
+      var names = [];
+      var pattern = /(?:^|[\s}])(?:query|mutation|subscription)\s+([_A-Za-z][_0-9A-Za-z]*)/g;
+      var match;
+      while ((match = pattern.exec(query)) !== null) {
+         names.push(match[1]);
+      }
+      return names;
+   }
+
+   function updateOperations() {
+      var names = operationNames(queryEditor.value);
+      var selected = operationSelect.value;
+      operationSelect.textContent = "";
+      names.forEach(function (name) {
+         var option = document.createElement("option");
+         option.value = name;
+         option.textContent = name;
+         operationSelect.appendChild(option);
+      });
+      if (names.indexOf(selected) !== -1) {
+         operationSelect.value = selected;
+      }
+      operationSelect.hidden = names.length < 2;
+   }
+
+   function run() {
+      var params = {query: queryEditor.value};
+      try {
+         params.variables = parseObject(variablesEditor.value, "variables");
+      } catch (error) {
+         showResult(error.message);
+         return;
+      }
+      if (operationSelect.hidden === false) {
+         params.operationName = operationSelect.value;
+      }
+
+      showResult("Loading...");
+      try {
+         execute(params).then(showResult, function (error) {
+            showResult("The request failed: " + error.message);
+         });
+      } catch (error) {
+         showResult(error.message);
+      }
+   }
+
+   // Editors
+
+   function setupEditor(editor, name, fallback) {
+      editor.value = load(name, fallback);
+      editor.addEventListener("input", function () {
+         save(name, editor.value);
+      });
+      editor.addEventListener("keydown", function (event) {
+         if (event.key === "Enter" && (event.ctrlKey || event.metaKey)) {
+            event.preventDefault();
+            run();
+         } else if (event.key === "Tab" && !event.shiftKey) {
+            event.preventDefault();
+            var start = editor.selectionStart;
+            editor.value = editor.value.slice(0, start) + "  " + editor.value.slice(editor.selectionEnd);
+            editor.selectionStart = editor.selectionEnd = start + 2;
+            save(name, editor.value);
+         }
+      });
+   }
+
+   var defaultHeaders = root.getAttribute("data-headers");
+   setupEditor(queryEditor, "query", "{\n  __typename\n}\n");
+   setupEditor(variablesEditor, "variables", "");
+   setupEditor(headersEditor, "headers", defaultHeaders === "{}" ? "" : defaultHeaders);
+
+   // A query in the url, e.g. from a shared link, replaces the stored one
+   var search = new URLSearchParams(window.location.search);
+   if (search.has("query")) {
+      queryEditor.value = search.get("query");
+      variablesEditor.value = search.get("variables") || "";
+   }
+
+   queryEditor.addEventListener("input", updateOperations);
+   headersEditor.addEventListener("input", function () {
+      schema = null;
+   });
+   updateOperations();
+
+   document.getElementById("run").addEventListener("click", run);
+
+   Array.prototype.forEach.call(document.querySelectorAll(".tabs button"), function (tab) {
+      tab.addEventListener("click", function () {
+         Array.prototype.forEach.call(document.querySelectorAll(".tabs button"), function (other) {
+            other.classList.toggle("active", other === tab);
+         });
+         variablesEditor.hidden = tab.getAttribute("data-tab") !== "variables";
+         headersEditor.hidden = tab.getAttribute("data-tab") !== "headers";
+      });
+   });
+
+   // Documentation
+
+   function element(tag, className, text) {
+      var node = document.createElement(tag);
+      if (className) {
+         node.className = className;
+      }
+      if (text) {
+         node.textContent = text;
+      }
+      return node;
+   }
+
+   function typeLink(name) {
+      var link = element("a", "", name);
+      link.addEventListener("click", function () {
+         showType(name, true);
+      });
+      return link;
+   }
+
+   // typeRef renders a type reference like [Todo!]! with a link to the
+   // named type
+   function typeRef(ref) {
+      var node = element("span");
+      if (ref.kind === "NON_NULL") {
+         node.appendChild(typeRef(ref.ofType));
+         node.appendChild(document.createTextNode("!"));
+      } else if (ref.kind === "LIST") {
+         node.appendChild(document.createTextNode("["));
+         node.appendChild(typeRef(ref.ofType));
+         node.appendChild(document.createTextNode("]"));
+      } else {
+         node.appendChild(typeLink(ref.name));
+      }
+      return node;
+   }
+
+   function description(text) {
+      return element("p", "description", text || "");
+   }
+
+   function field(item) {
+      var node = element("div", "field");
+      var name = element("span", "field-name" + (item.isDeprecated ? " deprecated" : ""), item.name);
+      node.appendChild(name);
+      if (item.args && item.args.length > 0) {
+         node.appendChild(document.createTextNode("("));
+         item.args.forEach(function (arg, i) {
+            if (i > 0) {
+               node.appendChild(document.createTextNode(", "));
+            }
+            node.appendChild(document.createTextNode(arg.name + ": "));
+            node.appendChild(typeRef(arg.type));
+            if (arg.defaultValue !== null && arg.defaultValue !== undefined) {
+               node.appendChild(document.createTextNode(" = " + arg.defaultValue));
+            }
+         });
+         node.appendChild(document.createTextNode(")"));
+      }
+      if (item.type) {
+         node.appendChild(document.createTextNode(": "));
+         node.appendChild(typeRef(item.type));
+      }
+      if (item.description) {
+         node.appendChild(description(item.description));
+      }
+      if (item.isDeprecated && item.deprecationReason) {
+         node.appendChild(description("Deprecated: " + item.deprecationReason));
+      }
+      return node;
+   }
+
+   function renderPath() {
+      docsPath.textContent = "";
+      var home = element("a", "", "Schema");
+      home.addEventListener("click", showRoot);
+      docsPath.appendChild(home);
+      docsHistory.forEach(function (name) {
+         docsPath.appendChild(document.createTextNode(" / "));
+         docsPath.appendChild(typeLink(name));
+      });
+   }
+
+   function showRoot() {
+      docsHistory = [];
+      renderPath();
+      docsContent.textContent = "";
+      docsContent.appendChild(element("h2", "", "Root types"));
+      [["query", schema.queryType], ["mutation", schema.mutationType], ["subscription", schema.subscriptionType]]
+         .forEach(function (entry) {
+            if (entry[1]) {
+               var node = element("div", "field");
+               node.appendChild(element("span", "field-name", entry[0]));
+               node.appendChild(document.createTextNode(": "));
+               node.appendChild(typeLink(entry[1].name));
+               docsContent.appendChild(node);
+            }
+         });
+
+      docsContent.appendChild(element("h2", "", "All types"));
+      schema.types.forEach(function (type) {
+         if (type.name.indexOf("__") !== 0) {
+            var node = element("div", "field");
+            node.appendChild(typeLink(type.name));
+            docsContent.appendChild(node);
+         }
+      });
+   }
+
+   function showType(name, push) {
+      var type = schema.types.filter(function (type) {
+         return type.name === name;
+      })[0];
+      if (!type) {
+         return;
+      }
+      if (push) {
+         var index = docsHistory.indexOf(name);
+         docsHistory = index === -1 ? docsHistory.concat([name]) : docsHistory.slice(0, index + 1);
+      }
+      renderPath();
+
+      docsContent.textContent = "";
+      docsContent.appendChild(element("h2", "", type.name));
+      docsContent.appendChild(description(type.kind.toLowerCase().replace("_", " ")));
+      if (type.description) {
+         docsContent.appendChild(description(type.description));
+      }
+
+      function section(title, items, render) {
+         if (items && items.length > 0) {
+            docsContent.appendChild(element("h3", "", title));
+            items.forEach(function (item) {
+               docsContent.appendChild(render(item));
+            });
+         }
+      }
+
+      section("Implements", type.interfaces, function (item) {
+         var node = element("div", "field");
+         node.appendChild(typeLink(item.name));
+         return node;
+      });
+      section("Fields", type.fields, field);
+      section("Input fields", type.inputFields, field);
+      section("Values", type.enumValues, field);
+      section("Possible types", type.possibleTypes, function (item) {
+         var node = element("div", "field");
+         node.appendChild(typeLink(item.name));
+         return node;
+      });
+   }
+
+   function showDocs() {
+      if (schema !== null) {
+         return;
+      }
+      docsPath.textContent = "";
+      docsContent.textContent = "Loading...";
+      try {
+         execute({query: introspectionQuery}).then(function (result) {
+            if (!result || !result.data || !result.data.__schema) {
+               var errors = result && result.errors ? result.errors : [];
+               docsContent.textContent = errors.length > 0 ? errors[0].message : "The schema could not be loaded";
+               return;
+            }
+            schema = result.data.__schema;
+            showRoot();
+         }, function (error) {
+            docsContent.textContent = "The schema could not be loaded: " + error.message;
+         });
+      } catch (error) {
+         docsContent.textContent = error.message;
+      }
+   }
+
+   document.getElementById("docs-toggle").addEventListener("click", function () {
+      docs.hidden = !docs.hidden;
+      if (docs.hidden === false) {
+         showDocs();
+      }
+   });
+})();
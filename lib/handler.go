@@ -0,0 +1,338 @@
+package lib
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// DefaultMaxBodySize is the request body limit used when
+// HandlerOptions.MaxBodySize is not set
+const DefaultMaxBodySize = 1 << 20
+
+// HandlerOptions configure the http handler of a schema. The zero value
+// serves POST requests at /graphql, and subscriptions at /subscriptions when
+// the schema has any
+type HandlerOptions struct {
+	// Path of the graphql endpoint, defaults to /graphql
+	Path string
+
+	// Path of the websocket endpoint for subscriptions, defaults to
+	// /subscriptions
+	SubscriptionPath string
+
+	// GET allows queries to be sent as GET requests, mutations always
+	// require a POST request
+	GET bool
+
+	// MaxBodySize is the maximum size of a request body in bytes, defaults to
+	// DefaultMaxBodySize
+	MaxBodySize int64
+
+	// AllowedOrigins are the origins allowed to make cross-origin requests
+	// and to open a subscription websocket, "*" allows any origin
+	AllowedOrigins []string
+
+	// Explorer serves the embedded schema explorer at ExplorerPath, a query
+	// editor with the documentation of the schema
+	Explorer bool
+
+	// Path of the schema explorer, defaults to /. The assets of the explorer
+	// are served below ExplorerAssetsPath(ExplorerPath)
+	ExplorerPath string
+
+	// ExplorerHeaders are the initial request headers of the schema
+	// explorer, e.g. an Authorization header to test authenticated queries
+	ExplorerHeaders map[string]string
+
+	// DisableIntrospection rejects queries that select the __schema or
+	// __type fields
+	DisableIntrospection bool
+}
+
+// RequestParams is the payload of a graphql request
+type RequestParams struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+}
+
+// NewHandler returns the http handler of a schema, subscribe is used for the
+// subscription endpoint and may be nil when the schema has no subscriptions
+func NewHandler(schema *graphql.Schema, subscribe SubscribeFunc, opts HandlerOptions) http.Handler {
+	if opts.Path == "" {
+		opts.Path = "/graphql"
+	}
+	if opts.SubscriptionPath == "" {
+		opts.SubscriptionPath = "/subscriptions"
+	}
+	if opts.ExplorerPath == "" {
+		opts.ExplorerPath = "/"
+	}
+	if opts.MaxBodySize <= 0 {
+		opts.MaxBodySize = DefaultMaxBodySize
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(opts.Path, &graphqlHandler{schema: schema, opts: opts})
+	if subscribe != nil {
+		mux.Handle(opts.SubscriptionPath, subscriptionHandler(subscribe, newUpgrader(opts.AllowedOrigins)))
+	}
+	if opts.Explorer == true {
+		explorer := ExplorerHandler(ExplorerOptions{
+			Path:     opts.ExplorerPath,
+			Endpoint: opts.Path,
+			Headers:  opts.ExplorerHeaders,
+		})
+		mux.Handle(opts.ExplorerPath, explorer)
+		mux.Handle(ExplorerAssetsPath(opts.ExplorerPath), explorer)
+	}
+
+	return cors(opts.AllowedOrigins, mux)
+}
+
+type graphqlHandler struct {
+	schema *graphql.Schema
+	opts   HandlerOptions
+}
+
+// requestError is an error with the http status of the response
+type requestError struct {
+	status  int
+	message string
+}
+
+func (e *requestError) Error() string {
+	return e.message
+}
+
+func (handler *graphqlHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	params, err := handler.params(r)
+	if err != nil {
+		status := http.StatusBadRequest
+		if reqErr, ok := err.(*requestError); ok == true {
+			status = reqErr.status
+		}
+		if status == http.StatusMethodNotAllowed {
+			w.Header().Set("Allow", handler.allow())
+		}
+		writeResult(w, status, errorResult(err.Error()))
+		return
+	}
+
+	// The query is parsed here only to check what it does, syntax errors are
+	// reported by graphql.Do
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{
+			Body: []byte(params.Query),
+			Name: "GraphQL request",
+		}),
+	})
+	if err == nil {
+		operation := operationType(doc, params.OperationName)
+		if r.Method == http.MethodGet && operation != "" && operation != ast.OperationTypeQuery {
+			w.Header().Set("Allow", http.MethodPost)
+			writeResult(w, http.StatusMethodNotAllowed, errorResult("only queries can be sent as GET requests"))
+			return
+		}
+		if handler.opts.DisableIntrospection == true && isIntrospection(doc) {
+			writeResult(w, http.StatusOK, errorResult("introspection is disabled"))
+			return
+		}
+	}
+
+	result := graphql.Do(graphql.Params{
+		Schema:         *handler.schema,
+		RequestString:  params.Query,
+		VariableValues: params.Variables,
+		OperationName:  params.OperationName,
+		Context:        WithLoaders(r.Context()),
+	})
+	writeResult(w, http.StatusOK, result)
+}
+
+func (handler *graphqlHandler) allow() string {
+	if handler.opts.GET == true {
+		return "GET, POST"
+	}
+	return http.MethodPost
+}
+
+// params reads the request params of a GET request, or of a POST request
+// with an application/json or application/graphql body
+func (handler *graphqlHandler) params(r *http.Request) (RequestParams, error) {
+	var params RequestParams
+
+	switch r.Method {
+	case http.MethodGet:
+		if handler.opts.GET == false {
+			return params, &requestError{http.StatusMethodNotAllowed, "GET requests are not allowed"}
+		}
+		query := r.URL.Query()
+		params.Query = query.Get("query")
+		params.OperationName = query.Get("operationName")
+		if variables := query.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &params.Variables); err != nil {
+				return params, &requestError{http.StatusBadRequest, "the variables are not valid JSON"}
+			}
+		}
+	case http.MethodPost:
+		body, err := ioutil.ReadAll(io.LimitReader(r.Body, handler.opts.MaxBodySize+1))
+		if err != nil {
+			return params, &requestError{http.StatusBadRequest, "the request body can't be read"}
+		}
+		if int64(len(body)) > handler.opts.MaxBodySize {
+			return params, &requestError{http.StatusRequestEntityTooLarge, "the request body is too large"}
+		}
+
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		switch mediaType {
+		case "application/graphql":
+			params.Query = string(body)
+		case "application/json", "":
+			if err := json.Unmarshal(body, &params); err != nil {
+				return params, &requestError{http.StatusBadRequest, "the request body is not valid JSON"}
+			}
+		default:
+			return params, &requestError{http.StatusUnsupportedMediaType,
+				"the content type must be application/json or application/graphql"}
+		}
+	default:
+		return params, &requestError{http.StatusMethodNotAllowed, r.Method + " requests are not allowed"}
+	}
+
+	if params.Query == "" {
+		return params, &requestError{http.StatusBadRequest, "the request has no query"}
+	}
+	return params, nil
+}
+
+// operationType returns the type of the operation that is executed, or an
+// empty string when the operation can't be found
+func operationType(doc *ast.Document, name string) string {
+	var operations []*ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		if operation, ok := def.(*ast.OperationDefinition); ok == true {
+			operations = append(operations, operation)
+		}
+	}
+
+	for _, operation := range operations {
+		if name == "" && len(operations) == 1 {
+			return operation.Operation
+		}
+		if operation.Name != nil && operation.Name.Value == name {
+			return operation.Operation
+		}
+	}
+	return ""
+}
+
+// isIntrospection reports whether the document selects the __schema or
+// __type field anywhere
+func isIntrospection(doc *ast.Document) bool {
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.OperationDefinition:
+			if selectsIntrospection(def.SelectionSet) {
+				return true
+			}
+		case *ast.FragmentDefinition:
+			if selectsIntrospection(def.SelectionSet) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func selectsIntrospection(set *ast.SelectionSet) bool {
+	if set == nil {
+		return false
+	}
+	for _, selection := range set.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if name := selection.Name.Value; name == "__schema" || name == "__type" {
+				return true
+			}
+			if selectsIntrospection(selection.SelectionSet) {
+				return true
+			}
+		case *ast.InlineFragment:
+			if selectsIntrospection(selection.SelectionSet) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// errorResponse is the response to a request that is rejected before it is
+// executed, it has no data
+type errorResponse struct {
+	Errors []errorMessage `json:"errors"`
+}
+
+type errorMessage struct {
+	Message string `json:"message"`
+}
+
+func errorResult(message string) errorResponse {
+	return errorResponse{Errors: []errorMessage{{Message: message}}}
+}
+
+func writeResult(w http.ResponseWriter, status int, result interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(result)
+}
+
+// cors answers preflight requests and adds the CORS headers for the allowed
+// origins
+func cors(allowed []string, next http.Handler) http.Handler {
+	if len(allowed) == 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" || originAllowed(allowed, origin) == false {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		header := w.Header()
+		header.Set("Access-Control-Allow-Origin", origin)
+		header.Add("Vary", "Origin")
+
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			header.Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+			if headers := r.Header.Get("Access-Control-Request-Headers"); headers != "" {
+				header.Set("Access-Control-Allow-Headers", headers)
+			}
+			header.Set("Access-Control-Max-Age", "600")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func originAllowed(allowed []string, origin string) bool {
+	for _, allow := range allowed {
+		if allow == "*" || strings.EqualFold(allow, origin) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,270 @@
+package lib
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/graphql-go/graphql"
+)
+
+func testSchema(t *testing.T) *graphql.Schema {
+	t.Helper()
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"hello": &graphql.Field{
+					Type: graphql.String,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return "world", nil
+					},
+				},
+			},
+		}),
+		Mutation: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Mutation",
+			Fields: graphql.Fields{
+				"bump": &graphql.Field{
+					Type: graphql.Int,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return 1, nil
+					},
+				},
+			},
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &schema
+}
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        HandlerOptions
+		method      string
+		target      string
+		contentType string
+		body        string
+		origin      string
+		status      int
+		response    string
+		allowOrigin string
+	}{
+		{
+			name:     "post json",
+			method:   http.MethodPost,
+			target:   "/graphql",
+			body:     `{"query": "{ hello }"}`,
+			status:   http.StatusOK,
+			response: `{"data":{"hello":"world"}}`,
+		},
+		{
+			name:        "post graphql",
+			method:      http.MethodPost,
+			target:      "/graphql",
+			contentType: "application/graphql",
+			body:        `{ hello }`,
+			status:      http.StatusOK,
+			response:    `{"data":{"hello":"world"}}`,
+		},
+		{
+			name:        "unsupported content type",
+			method:      http.MethodPost,
+			target:      "/graphql",
+			contentType: "text/plain",
+			body:        `{ hello }`,
+			status:      http.StatusUnsupportedMediaType,
+		},
+		{
+			name:   "invalid json",
+			method: http.MethodPost,
+			target: "/graphql",
+			body:   `{"query": `,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "no query",
+			method: http.MethodPost,
+			target: "/graphql",
+			body:   `{}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "body too large",
+			opts:   HandlerOptions{MaxBodySize: 8},
+			method: http.MethodPost,
+			target: "/graphql",
+			body:   `{"query": "{ hello }"}`,
+			status: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:   "get disabled",
+			method: http.MethodGet,
+			target: "/graphql?query={hello}",
+			status: http.StatusMethodNotAllowed,
+		},
+		{
+			name:     "get query",
+			opts:     HandlerOptions{GET: true},
+			method:   http.MethodGet,
+			target:   "/graphql?query={hello}",
+			status:   http.StatusOK,
+			response: `{"data":{"hello":"world"}}`,
+		},
+		{
+			name:   "get mutation",
+			opts:   HandlerOptions{GET: true},
+			method: http.MethodGet,
+			target: "/graphql?query=mutation{bump}",
+			status: http.StatusMethodNotAllowed,
+		},
+		{
+			name:     "custom path",
+			opts:     HandlerOptions{Path: "/api"},
+			method:   http.MethodPost,
+			target:   "/api",
+			body:     `{"query": "{ hello }"}`,
+			status:   http.StatusOK,
+			response: `{"data":{"hello":"world"}}`,
+		},
+		{
+			name:     "introspection disabled",
+			opts:     HandlerOptions{DisableIntrospection: true},
+			method:   http.MethodPost,
+			target:   "/graphql",
+			body:     `{"query": "{ __schema { queryType { name } } }"}`,
+			status:   http.StatusOK,
+			response: `{"errors":[{"message":"introspection is disabled"}]}`,
+		},
+		{
+			name:        "allowed origin",
+			opts:        HandlerOptions{AllowedOrigins: []string{"https://app.example.com"}},
+			method:      http.MethodPost,
+			target:      "/graphql",
+			body:        `{"query": "{ hello }"}`,
+			origin:      "https://app.example.com",
+			status:      http.StatusOK,
+			allowOrigin: "https://app.example.com",
+		},
+		{
+			name:   "other origin",
+			opts:   HandlerOptions{AllowedOrigins: []string{"https://app.example.com"}},
+			method: http.MethodPost,
+			target: "/graphql",
+			body:   `{"query": "{ hello }"}`,
+			origin: "https://other.example.com",
+			status: http.StatusOK,
+		},
+		{
+			name:        "preflight",
+			opts:        HandlerOptions{AllowedOrigins: []string{"*"}},
+			method:      http.MethodOptions,
+			target:      "/graphql",
+			origin:      "https://app.example.com",
+			status:      http.StatusNoContent,
+			allowOrigin: "https://app.example.com",
+		},
+		{
+			name:   "explorer disabled",
+			method: http.MethodGet,
+			target: "/",
+			status: http.StatusNotFound,
+		},
+		{
+			name:   "explorer",
+			opts:   HandlerOptions{Explorer: true},
+			method: http.MethodGet,
+			target: "/",
+			status: http.StatusOK,
+		},
+	}
+
+	schema := testSchema(t)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(test.method, test.target, strings.NewReader(test.body))
+			if test.contentType != "" {
+				r.Header.Set("Content-Type", test.contentType)
+			}
+			if test.origin != "" {
+				r.Header.Set("Origin", test.origin)
+			}
+			if test.method == http.MethodOptions {
+				r.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			}
+
+			w := httptest.NewRecorder()
+			NewHandler(schema, nil, test.opts).ServeHTTP(w, r)
+
+			if w.Code != test.status {
+				t.Errorf("status %d, expected %d: %s", w.Code, test.status, w.Body)
+			}
+			if body := strings.TrimSpace(w.Body.String()); test.response != "" && body != test.response {
+				t.Errorf("response %s, expected %s", body, test.response)
+			}
+			if origin := w.Header().Get("Access-Control-Allow-Origin"); origin != test.allowOrigin {
+				t.Errorf("allowed origin %q, expected %q", origin, test.allowOrigin)
+			}
+		})
+	}
+}
+
+func TestHandlerSubscriptionOrigin(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		origin  string
+		ok      bool
+	}{
+		{"no origin", nil, "", true},
+		{"same origin", []string{"https://app.example.com"}, "self", true},
+		{"other origin", nil, "https://app.example.com", false},
+		{"allowed origin", []string{"https://app.example.com"}, "https://app.example.com", true},
+		{"any origin", []string{"*"}, "https://app.example.com", true},
+		{"not allowed origin", []string{"https://app.example.com"}, "https://other.example.com", false},
+	}
+
+	subscribe := func(ctx context.Context, params SubscriptionParams) chan *graphql.Result {
+		results := make(chan *graphql.Result)
+		close(results)
+		return results
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(NewHandler(testSchema(t), subscribe, HandlerOptions{
+				AllowedOrigins: test.allowed,
+			}))
+			defer server.Close()
+
+			header := http.Header{}
+			if test.origin == "self" {
+				header.Set("Origin", server.URL)
+			} else if test.origin != "" {
+				header.Set("Origin", test.origin)
+			}
+			url := "ws" + strings.TrimPrefix(server.URL, "http") + "/subscriptions"
+			conn, response, err := websocket.DefaultDialer.Dial(url, header)
+			if test.ok == true {
+				if err != nil {
+					t.Fatalf("handshake failed: %s", err)
+				}
+				conn.Close()
+				return
+			}
+			if err == nil {
+				conn.Close()
+				t.Fatal("handshake succeeded, expected it to be rejected")
+			}
+			if response == nil || response.StatusCode != http.StatusForbidden {
+				t.Fatalf("handshake failed with %v, expected status 403", err)
+			}
+		})
+	}
+}
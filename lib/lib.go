@@ -11,13 +11,23 @@ type IDFieldInterface interface {
 	IdField(context.Context) (*string, error)
 }
 
+// NonNullIDFieldInterface is implemented by nodes with a non-null id field
+type NonNullIDFieldInterface interface {
+	IdField(context.Context) (string, error)
+}
+
 func IDFetchFunction(obj interface{}, info graphql.ResolveInfo, ctx context.Context) (string, error) {
-	field, ok := obj.(IDFieldInterface)
-	if ok == false {
-		return "", errors.New("Could not resolve the id")
+	switch field := obj.(type) {
+	case NonNullIDFieldInterface:
+		return field.IdField(ctx)
+	case IDFieldInterface:
+		id, err := field.IdField(ctx)
+		if err != nil || id == nil {
+			return "", err
+		}
+		return *id, nil
 	}
-	id, err := field.IdField(ctx)
-	return *id, err
+	return "", errors.New("Could not resolve the id")
 }
 
 type MutationPayload struct {
@@ -29,7 +39,13 @@ type ClientMutationID struct {
 	ClientMutationId string
 }
 
-func AddFieldConfigMap(obj *graphql.Object, fields graphql.Fields) {
+// FieldConfigAdder is implemented by graphql types that accept fields after
+// they have been created, i.e. objects and interfaces
+type FieldConfigAdder interface {
+	AddFieldConfig(string, *graphql.Field)
+}
+
+func AddFieldConfigMap(obj FieldConfigAdder, fields graphql.Fields) {
 	for name, field := range fields {
 		obj.AddFieldConfig(name, field)
 	}
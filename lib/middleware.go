@@ -0,0 +1,71 @@
+package lib
+
+import (
+	"context"
+
+	"github.com/graphql-go/graphql"
+)
+
+// FieldInfo describes the field a resolver is called for
+type FieldInfo struct {
+	// ParentType is the name of the object type the field is resolved on
+	ParentType string
+
+	// FieldName is the name of the field in the schema
+	FieldName string
+
+	// Path is the path of the field in the response, made up of field names
+	// and list indexes
+	Path []interface{}
+
+	// Args are the decoded arguments of the field by name, as they are passed
+	// to the adapter
+	Args map[string]interface{}
+
+	// Source is the object the field is resolved on, i.e. the value returned
+	// by the adapter of the parent field
+	Source interface{}
+}
+
+// Resolver resolves a field by calling its adapter with the context
+type Resolver func(ctx context.Context) (interface{}, error)
+
+// Middleware is called instead of the resolver of every field that is
+// resolved by an adapter, it calls next to resolve the field, possibly with a
+// different context. The value of a subscription field is the channel of
+// events, and the value of a batched field is the thunk that resolves the
+// field once the batch is loaded
+type Middleware func(ctx context.Context, info FieldInfo, next Resolver) (interface{}, error)
+
+// Chain returns a middleware that calls the middlewares in order, the first
+// middleware is the outermost one
+func Chain(middlewares ...Middleware) Middleware {
+	return func(ctx context.Context, info FieldInfo, next Resolver) (interface{}, error) {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			middleware, inner := middlewares[i], next
+			next = func(ctx context.Context) (interface{}, error) {
+				return middleware(ctx, info, inner)
+			}
+		}
+		return next(ctx)
+	}
+}
+
+// Resolve resolves a field through the middleware, next is called directly
+// when there is no middleware
+func (middleware Middleware) Resolve(params graphql.ResolveParams, args map[string]interface{}, next Resolver) (interface{}, error) {
+	if middleware == nil {
+		return next(params.Context)
+	}
+
+	info := FieldInfo{
+		FieldName: params.Info.FieldName,
+		Path:      params.Info.Path.AsArray(),
+		Args:      args,
+		Source:    params.Source,
+	}
+	if params.Info.ParentType != nil {
+		info.ParentType = params.Info.ParentType.Name()
+	}
+	return middleware(params.Context, info, next)
+}
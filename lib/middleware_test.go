@@ -0,0 +1,207 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+)
+
+type contextKey string
+
+// tracer returns a middleware that logs when it's entered and left
+func tracer(name string, log *[]string) Middleware {
+	return func(ctx context.Context, info FieldInfo, next Resolver) (interface{}, error) {
+		*log = append(*log, ">"+name)
+		value, err := next(ctx)
+		*log = append(*log, "<"+name)
+		return value, err
+	}
+}
+
+func TestChain(t *testing.T) {
+	tests := []struct {
+		name        string
+		middlewares func(log *[]string) []Middleware
+		log         []string
+		value       interface{}
+		err         string
+	}{
+		{
+			name:        "no middlewares",
+			middlewares: func(log *[]string) []Middleware { return nil },
+			log:         []string{"resolve"},
+			value:       "value",
+		},
+		{
+			name: "outermost first",
+			middlewares: func(log *[]string) []Middleware {
+				return []Middleware{tracer("a", log), tracer("b", log), tracer("c", log)}
+			},
+			log:   []string{">a", ">b", ">c", "resolve", "<c", "<b", "<a"},
+			value: "value",
+		},
+		{
+			name: "short circuit",
+			middlewares: func(log *[]string) []Middleware {
+				return []Middleware{
+					tracer("a", log),
+					func(ctx context.Context, info FieldInfo, next Resolver) (interface{}, error) {
+						return nil, errors.New("denied")
+					},
+					tracer("c", log),
+				}
+			},
+			log: []string{">a", "<a"},
+			err: "denied",
+		},
+		{
+			name: "context",
+			middlewares: func(log *[]string) []Middleware {
+				return []Middleware{
+					func(ctx context.Context, info FieldInfo, next Resolver) (interface{}, error) {
+						return next(context.WithValue(ctx, contextKey("user"), "alice"))
+					},
+				}
+			},
+			log:   []string{"resolve"},
+			value: "alice",
+		},
+		{
+			name: "value",
+			middlewares: func(log *[]string) []Middleware {
+				return []Middleware{
+					func(ctx context.Context, info FieldInfo, next Resolver) (interface{}, error) {
+						value, err := next(ctx)
+						return strings.ToUpper(value.(string)), err
+					},
+				}
+			},
+			log:   []string{"resolve"},
+			value: "VALUE",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var log []string
+			resolve := func(ctx context.Context) (interface{}, error) {
+				log = append(log, "resolve")
+				if user, ok := ctx.Value(contextKey("user")).(string); ok == true {
+					return user, nil
+				}
+				return "value", nil
+			}
+
+			value, err := Chain(test.middlewares(&log)...)(context.Background(), FieldInfo{}, resolve)
+			if test.err != "" {
+				if err == nil || err.Error() != test.err {
+					t.Errorf("error %v, expected %q", err, test.err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if value != test.value {
+				t.Errorf("value %v, expected %v", value, test.value)
+			}
+			if reflect.DeepEqual(log, test.log) == false {
+				t.Errorf("log %v, expected %v", log, test.log)
+			}
+		})
+	}
+}
+
+func TestMiddlewareResolve(t *testing.T) {
+	var infos []FieldInfo
+	middleware := Middleware(func(ctx context.Context, info FieldInfo, next Resolver) (interface{}, error) {
+		infos = append(infos, info)
+		return next(ctx)
+	})
+
+	// resolve resolves the fields through the middleware like the generated
+	// definitions do
+	resolve := func(value func(p graphql.ResolveParams) interface{}) graphql.FieldResolveFn {
+		return func(p graphql.ResolveParams) (interface{}, error) {
+			return middleware.Resolve(p, p.Args, func(ctx context.Context) (interface{}, error) {
+				return value(p), nil
+			})
+		}
+	}
+
+	todo := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Todo",
+		Fields: graphql.Fields{
+			"title": &graphql.Field{
+				Type: graphql.String,
+				Resolve: resolve(func(p graphql.ResolveParams) interface{} {
+					return p.Source
+				}),
+			},
+		},
+	})
+	user := graphql.NewObject(graphql.ObjectConfig{
+		Name: "User",
+		Fields: graphql.Fields{
+			"todos": &graphql.Field{
+				Type: graphql.NewList(todo),
+				Resolve: resolve(func(p graphql.ResolveParams) interface{} {
+					return []interface{}{"one", "two"}
+				}),
+			},
+		},
+	})
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"user": &graphql.Field{
+					Type: user,
+					Args: graphql.FieldConfigArgument{
+						"id": &graphql.ArgumentConfig{Type: graphql.ID},
+					},
+					Resolve: resolve(func(p graphql.ResolveParams) interface{} {
+						return struct{}{}
+					}),
+				},
+			},
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: `{ user(id: "1") { todos { title } } }`,
+		RootObject:    map[string]interface{}{"root": true},
+		Context:       context.Background(),
+	})
+	if len(result.Errors) > 0 {
+		t.Fatal(result.Errors)
+	}
+
+	expected := []FieldInfo{
+		{ParentType: "Query", FieldName: "user", Path: []interface{}{"user"}, Args: map[string]interface{}{"id": "1"},
+			Source: map[string]interface{}{"root": true}},
+		{ParentType: "User", FieldName: "todos", Path: []interface{}{"user", "todos"}, Args: map[string]interface{}{}, Source: struct{}{}},
+		{ParentType: "Todo", FieldName: "title", Path: []interface{}{"user", "todos", 0, "title"}, Args: map[string]interface{}{}, Source: "one"},
+		{ParentType: "Todo", FieldName: "title", Path: []interface{}{"user", "todos", 1, "title"}, Args: map[string]interface{}{}, Source: "two"},
+	}
+	if reflect.DeepEqual(infos, expected) == false {
+		t.Errorf("field infos:\n%+v\nexpected:\n%+v", infos, expected)
+	}
+}
+
+func TestMiddlewareResolveWithoutMiddleware(t *testing.T) {
+	var middleware Middleware
+	value, err := middleware.Resolve(graphql.ResolveParams{Context: context.Background()}, nil,
+		func(ctx context.Context) (interface{}, error) {
+			return "value", nil
+		})
+	if value != "value" || err != nil {
+		t.Errorf("value %v, error %v, expected the value of next", value, err)
+	}
+}
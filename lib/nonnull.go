@@ -0,0 +1,48 @@
+package lib
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/graphql-go/graphql"
+)
+
+// NonNullError is returned when the resolver of a non-null field returns nil
+type NonNullError struct {
+	Field string
+}
+
+func (e *NonNullError) Error() string {
+	return fmt.Sprintf("non-null field %s resolved to nil", e.Field)
+}
+
+// NonNull wraps the resolver of a non-null field, a nil value returned by the
+// resolver is turned into a NonNullError. Typed nil values, like a nil pointer
+// in an interface, are caught as well since graphql-go would try to resolve
+// their fields
+func NonNull(field string, resolve graphql.FieldResolveFn) graphql.FieldResolveFn {
+	return func(params graphql.ResolveParams) (interface{}, error) {
+		value, err := resolve(params)
+		if err != nil {
+			return nil, err
+		}
+		if IsNil(value) == true {
+			return nil, &NonNullError{Field: field}
+		}
+		return value, nil
+	}
+}
+
+// IsNil reports whether the value is nil or a nil pointer, slice, map, channel,
+// function or interface
+func IsNil(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+
+	switch v := reflect.ValueOf(value); v.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Chan, reflect.Func, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,358 @@
+// Package pagination implements the relay cursor pagination of connections.
+//
+// A Page describes the part of a list that is returned by a connection field,
+// it can be computed from a slice (FromSlice), from an offset/limit query
+// (NewOffset) or from a keyset query (NewKeyset). The bounds of the page point
+// into the list of items the adapter has at hand:
+//
+//	page, err := pagination.FromSlice(args.Arguments, len(todos))
+//	if err != nil {
+//		return nil, err
+//	}
+//	connection := &schema.TodoConnection{PageInfo: page.PageInfo()}
+//	for i, todo := range todos[page.Start:page.End] {
+//		connection.Edges = append(connection.Edges, &schema.TodoEdge{
+//			Node:   todo,
+//			Cursor: page.Cursors[i],
+//		})
+//	}
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/granateio/granate/lib"
+)
+
+// Arguments are the relay pagination arguments of a connection field, the
+// generated argument struct of every connection field embeds them
+type Arguments struct {
+	First  *int
+	After  *string
+	Last   *int
+	Before *string
+}
+
+// Page is the part of a list returned by a connection. Start and End are the
+// bounds of the page in the list of items, Cursors holds the cursor of every
+// item in the page
+type Page struct {
+	Start           int
+	End             int
+	Cursors         []string
+	HasPreviousPage bool
+	HasNextPage     bool
+}
+
+// Len returns the number of items in the page
+func (page Page) Len() int {
+	return page.End - page.Start
+}
+
+// PageInfo returns the relay page info of the page
+func (page Page) PageInfo() lib.PageInfo {
+	info := lib.PageInfo{
+		HasPreviousPage: page.HasPreviousPage,
+		HasNextPage:     page.HasNextPage,
+	}
+	if len(page.Cursors) > 0 {
+		start, end := page.Cursors[0], page.Cursors[len(page.Cursors)-1]
+		info.StartCursor = &start
+		info.EndCursor = &end
+	}
+	return info
+}
+
+const offsetPrefix = "offset:"
+
+// OffsetCursor returns the cursor of the item at the offset
+func OffsetCursor(offset int) string {
+	return base64.StdEncoding.EncodeToString([]byte(offsetPrefix + strconv.Itoa(offset)))
+}
+
+// CursorOffset returns the offset of an offset cursor
+func CursorOffset(cursor string) (int, error) {
+	decoded, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil || strings.HasPrefix(string(decoded), offsetPrefix) == false {
+		return 0, invalidCursor("cursor", cursor)
+	}
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(decoded), offsetPrefix))
+	if err != nil || offset < 0 {
+		return 0, invalidCursor("cursor", cursor)
+	}
+	return offset, nil
+}
+
+// KeyCursor returns a cursor for the key of an item, the key can be any value
+// that can be encoded as JSON, e.g. an id or a struct with the columns the
+// items are sorted by
+func KeyCursor(key interface{}) (string, error) {
+	encoded, err := json.Marshal(key)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(encoded), nil
+}
+
+// CursorKey decodes the key of a cursor returned by KeyCursor into key
+func CursorKey(cursor string, key interface{}) error {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || json.Unmarshal(decoded, key) != nil {
+		return invalidCursor("cursor", cursor)
+	}
+	return nil
+}
+
+func invalidCursor(path string, cursor string) error {
+	return &lib.ArgumentError{Path: path, Message: strconv.Quote(cursor) + " is not a valid cursor"}
+}
+
+func count(value *int, path string) (int, error) {
+	if *value < 0 {
+		return 0, &lib.ArgumentError{Path: path, Message: "must not be negative"}
+	}
+	return *value, nil
+}
+
+func cursorOffset(cursor *string, path string) (int, error) {
+	offset, err := CursorOffset(*cursor)
+	if err != nil {
+		return 0, invalidCursor(path, *cursor)
+	}
+	return offset, nil
+}
+
+func offsetCursors(offset int, n int) []string {
+	cursors := make([]string, n)
+	for i := range cursors {
+		cursors[i] = OffsetCursor(offset + i)
+	}
+	return cursors
+}
+
+// FromSlice returns the page of a list of the given length, the cursors are
+// offset cursors
+func FromSlice(args Arguments, length int) (Page, error) {
+	start, end := 0, length
+
+	if args.After != nil {
+		after, err := cursorOffset(args.After, "after")
+		if err != nil {
+			return Page{}, err
+		}
+		if after+1 > start {
+			start = after + 1
+		}
+	}
+	if args.Before != nil {
+		before, err := cursorOffset(args.Before, "before")
+		if err != nil {
+			return Page{}, err
+		}
+		if before < end {
+			end = before
+		}
+	}
+	if start > length {
+		start = length
+	}
+	if end < start {
+		end = start
+	}
+
+	if args.First != nil {
+		first, err := count(args.First, "first")
+		if err != nil {
+			return Page{}, err
+		}
+		if end-start > first {
+			end = start + first
+		}
+	}
+	if args.Last != nil {
+		last, err := count(args.Last, "last")
+		if err != nil {
+			return Page{}, err
+		}
+		if end-start > last {
+			start = end - last
+		}
+	}
+
+	return Page{
+		Start:           start,
+		End:             end,
+		Cursors:         offsetCursors(start, end-start),
+		HasPreviousPage: start > 0,
+		HasNextPage:     end < length,
+	}, nil
+}
+
+// OffsetQuery is the window of an offset/limit query for a page, fetch
+// Limit+1 items starting at Offset so the next page can be detected
+type OffsetQuery struct {
+	Offset int
+	Limit  int
+
+	before bool
+}
+
+// NewOffset returns the offset/limit query for the arguments, defaultLimit is
+// used when neither first nor last is set. Paginating backwards with last
+// requires a before cursor since the total number of items is unknown
+func NewOffset(args Arguments, defaultLimit int) (OffsetQuery, error) {
+	query := OffsetQuery{Limit: defaultLimit}
+
+	end := -1
+	if args.Before != nil {
+		before, err := cursorOffset(args.Before, "before")
+		if err != nil {
+			return query, err
+		}
+		end = before
+		query.before = true
+	}
+
+	if args.Last != nil && args.First == nil {
+		last, err := count(args.Last, "last")
+		if err != nil {
+			return query, err
+		}
+		if end == -1 {
+			return query, &lib.ArgumentError{Path: "last", Message: "can only be used together with before"}
+		}
+		query.Offset = end - last
+		if query.Offset < 0 {
+			query.Offset = 0
+		}
+		query.Limit = end - query.Offset
+		return query, nil
+	}
+
+	if args.After != nil {
+		after, err := cursorOffset(args.After, "after")
+		if err != nil {
+			return query, err
+		}
+		query.Offset = after + 1
+	}
+	if args.First != nil {
+		first, err := count(args.First, "first")
+		if err != nil {
+			return query, err
+		}
+		query.Limit = first
+	}
+	if end != -1 && query.Offset+query.Limit > end {
+		query.Limit = end - query.Offset
+		if query.Limit < 0 {
+			query.Limit = 0
+		}
+	}
+
+	return query, nil
+}
+
+// Page returns the page of the fetched items
+func (query OffsetQuery) Page(fetched int) Page {
+	end := fetched
+	if end > query.Limit {
+		end = query.Limit
+	}
+	return Page{
+		Start:           0,
+		End:             end,
+		Cursors:         offsetCursors(query.Offset, end),
+		HasPreviousPage: query.Offset > 0,
+		HasNextPage:     fetched > query.Limit || query.before,
+	}
+}
+
+// KeysetQuery is the window of a keyset query for a page. Fetch Limit+1 items
+// with a key after the After cursor and before the Before cursor, in key
+// order, or in reverse key order when Backward is set
+type KeysetQuery struct {
+	After    *string
+	Before   *string
+	Limit    int
+	Backward bool
+}
+
+// NewKeyset returns the keyset query for the arguments, defaultLimit is used
+// when neither first nor last is set
+func NewKeyset(args Arguments, defaultLimit int) (KeysetQuery, error) {
+	query := KeysetQuery{
+		After:  args.After,
+		Before: args.Before,
+		Limit:  defaultLimit,
+	}
+
+	switch {
+	case args.First != nil:
+		first, err := count(args.First, "first")
+		if err != nil {
+			return query, err
+		}
+		query.Limit = first
+	case args.Last != nil:
+		last, err := count(args.Last, "last")
+		if err != nil {
+			return query, err
+		}
+		query.Limit = last
+		query.Backward = true
+	}
+
+	return query, nil
+}
+
+// AfterKey decodes the key of the After cursor, false is returned when the
+// cursor is not set
+func (query KeysetQuery) AfterKey(key interface{}) (bool, error) {
+	if query.After == nil {
+		return false, nil
+	}
+	if err := CursorKey(*query.After, key); err != nil {
+		return false, invalidCursor("after", *query.After)
+	}
+	return true, nil
+}
+
+// BeforeKey decodes the key of the Before cursor, false is returned when the
+// cursor is not set
+func (query KeysetQuery) BeforeKey(key interface{}) (bool, error) {
+	if query.Before == nil {
+		return false, nil
+	}
+	if err := CursorKey(*query.Before, key); err != nil {
+		return false, invalidCursor("before", *query.Before)
+	}
+	return true, nil
+}
+
+// Page returns the page of the fetched items from their cursors. The cursors
+// must be in key order, so items fetched backwards have to be reversed first
+func (query KeysetQuery) Page(cursors []string) Page {
+	page := Page{Start: 0, End: len(cursors)}
+	more := len(cursors) > query.Limit
+
+	if query.Backward == true {
+		if more {
+			page.Start = len(cursors) - query.Limit
+		}
+		page.HasPreviousPage = more
+		page.HasNextPage = query.Before != nil
+	} else {
+		if more {
+			page.End = query.Limit
+		}
+		page.HasPreviousPage = query.After != nil
+		page.HasNextPage = more
+	}
+
+	page.Cursors = cursors[page.Start:page.End]
+	return page
+}
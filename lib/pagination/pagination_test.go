@@ -0,0 +1,228 @@
+package pagination
+
+import (
+	"reflect"
+	"testing"
+)
+
+func intp(value int) *int {
+	return &value
+}
+
+func cursor(offset int) *string {
+	cursor := OffsetCursor(offset)
+	return &cursor
+}
+
+func stringp(value string) *string {
+	return &value
+}
+
+func TestFromSlice(t *testing.T) {
+	tests := []struct {
+		name string
+		args Arguments
+		page Page
+		err  string
+	}{
+		{"all", Arguments{}, Page{Start: 0, End: 5}, ""},
+		{"first", Arguments{First: intp(2)}, Page{Start: 0, End: 2, HasNextPage: true}, ""},
+		{"first zero", Arguments{First: intp(0)}, Page{Start: 0, End: 0, HasNextPage: true}, ""},
+		{"first more than length", Arguments{First: intp(10)}, Page{Start: 0, End: 5}, ""},
+		{"last", Arguments{Last: intp(2)}, Page{Start: 3, End: 5, HasPreviousPage: true}, ""},
+		{"last more than length", Arguments{Last: intp(10)}, Page{Start: 0, End: 5}, ""},
+		{"first and last", Arguments{First: intp(3), Last: intp(2)},
+			Page{Start: 1, End: 3, HasPreviousPage: true, HasNextPage: true}, ""},
+		{"after", Arguments{After: cursor(1)}, Page{Start: 2, End: 5, HasPreviousPage: true}, ""},
+		{"after and first", Arguments{After: cursor(1), First: intp(2)},
+			Page{Start: 2, End: 4, HasPreviousPage: true, HasNextPage: true}, ""},
+		{"after the last item", Arguments{After: cursor(4)}, Page{Start: 5, End: 5, HasPreviousPage: true}, ""},
+		{"after the end", Arguments{After: cursor(10)}, Page{Start: 5, End: 5, HasPreviousPage: true}, ""},
+		{"before", Arguments{Before: cursor(3)}, Page{Start: 0, End: 3, HasNextPage: true}, ""},
+		{"before and last", Arguments{Before: cursor(3), Last: intp(2)},
+			Page{Start: 1, End: 3, HasPreviousPage: true, HasNextPage: true}, ""},
+		{"before the first item", Arguments{Before: cursor(0)}, Page{Start: 0, End: 0, HasNextPage: true}, ""},
+		{"after and before", Arguments{After: cursor(1), Before: cursor(3)},
+			Page{Start: 2, End: 3, HasPreviousPage: true, HasNextPage: true}, ""},
+		{"before ahead of after", Arguments{After: cursor(3), Before: cursor(1)},
+			Page{Start: 4, End: 4, HasPreviousPage: true, HasNextPage: true}, ""},
+		{"negative first", Arguments{First: intp(-1)}, Page{}, "argument first: must not be negative"},
+		{"negative last", Arguments{Last: intp(-1)}, Page{}, "argument last: must not be negative"},
+		{"invalid after", Arguments{After: stringp("bad")}, Page{}, `argument after: "bad" is not a valid cursor`},
+		{"invalid before", Arguments{Before: stringp("bad")}, Page{}, `argument before: "bad" is not a valid cursor`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			page, err := FromSlice(test.args, 5)
+			if test.err != "" {
+				if err == nil || err.Error() != test.err {
+					t.Fatalf("error %v, expected %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			test.page.Cursors = offsetCursors(test.page.Start, test.page.Len())
+			if reflect.DeepEqual(page, test.page) == false {
+				t.Errorf("page %+v, expected %+v", page, test.page)
+			}
+		})
+	}
+}
+
+func TestPageInfo(t *testing.T) {
+	page, _ := FromSlice(Arguments{After: cursor(0), First: intp(2)}, 5)
+	info := page.PageInfo()
+	if *info.StartCursor != OffsetCursor(1) || *info.EndCursor != OffsetCursor(2) {
+		t.Errorf("cursors %s and %s, expected the cursors of 1 and 2", *info.StartCursor, *info.EndCursor)
+	}
+	if info.HasPreviousPage == false || info.HasNextPage == false {
+		t.Errorf("expected a previous and a next page, got %+v", info)
+	}
+
+	page, _ = FromSlice(Arguments{First: intp(0)}, 5)
+	if info := page.PageInfo(); info.StartCursor != nil || info.EndCursor != nil {
+		t.Errorf("expected no cursors for an empty page, got %+v", info)
+	}
+}
+
+func TestNewOffset(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  Arguments
+		query OffsetQuery
+		err   string
+	}{
+		{"default", Arguments{}, OffsetQuery{Offset: 0, Limit: 10}, ""},
+		{"first", Arguments{First: intp(5)}, OffsetQuery{Offset: 0, Limit: 5}, ""},
+		{"after and first", Arguments{After: cursor(2), First: intp(5)}, OffsetQuery{Offset: 3, Limit: 5}, ""},
+		{"before and last", Arguments{Before: cursor(5), Last: intp(2)}, OffsetQuery{Offset: 3, Limit: 2, before: true}, ""},
+		{"last before the start", Arguments{Before: cursor(1), Last: intp(5)}, OffsetQuery{Offset: 0, Limit: 1, before: true}, ""},
+		{"after and before", Arguments{After: cursor(2), Before: cursor(4)}, OffsetQuery{Offset: 3, Limit: 1, before: true}, ""},
+		{"before ahead of after", Arguments{After: cursor(4), Before: cursor(2)}, OffsetQuery{Offset: 5, Limit: 0, before: true}, ""},
+		{"last without before", Arguments{Last: intp(2)}, OffsetQuery{}, "argument last: can only be used together with before"},
+		{"negative first", Arguments{First: intp(-1)}, OffsetQuery{}, "argument first: must not be negative"},
+		{"invalid after", Arguments{After: stringp("bad")}, OffsetQuery{}, `argument after: "bad" is not a valid cursor`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			query, err := NewOffset(test.args, 10)
+			if test.err != "" {
+				if err == nil || err.Error() != test.err {
+					t.Fatalf("error %v, expected %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if query != test.query {
+				t.Errorf("query %+v, expected %+v", query, test.query)
+			}
+		})
+	}
+}
+
+func TestOffsetQueryPage(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   OffsetQuery
+		fetched int
+		page    Page
+	}{
+		{"more items", OffsetQuery{Offset: 3, Limit: 2}, 3,
+			Page{Start: 0, End: 2, Cursors: offsetCursors(3, 2), HasPreviousPage: true, HasNextPage: true}},
+		{"last items", OffsetQuery{Offset: 0, Limit: 2}, 2,
+			Page{Start: 0, End: 2, Cursors: offsetCursors(0, 2)}},
+		{"no items", OffsetQuery{Offset: 0, Limit: 2}, 0,
+			Page{Start: 0, End: 0, Cursors: []string{}}},
+		{"before", OffsetQuery{Offset: 1, Limit: 2, before: true}, 2,
+			Page{Start: 0, End: 2, Cursors: offsetCursors(1, 2), HasPreviousPage: true, HasNextPage: true}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if page := test.query.Page(test.fetched); reflect.DeepEqual(page, test.page) == false {
+				t.Errorf("page %+v, expected %+v", page, test.page)
+			}
+		})
+	}
+}
+
+func TestKeyset(t *testing.T) {
+	type key struct {
+		ID int
+	}
+	cursors := make([]string, 3)
+	for i := range cursors {
+		cursors[i], _ = KeyCursor(key{ID: i + 1})
+	}
+
+	tests := []struct {
+		name  string
+		args  Arguments
+		query KeysetQuery
+		page  Page
+	}{
+		{"first", Arguments{First: intp(2)}, KeysetQuery{Limit: 2},
+			Page{Start: 0, End: 2, Cursors: cursors[:2], HasNextPage: true}},
+		{"after", Arguments{After: &cursors[0], First: intp(5)}, KeysetQuery{After: &cursors[0], Limit: 5},
+			Page{Start: 0, End: 3, Cursors: cursors, HasPreviousPage: true}},
+		{"last", Arguments{Last: intp(2)}, KeysetQuery{Limit: 2, Backward: true},
+			Page{Start: 1, End: 3, Cursors: cursors[1:], HasPreviousPage: true}},
+		{"before", Arguments{Before: &cursors[2], Last: intp(5)}, KeysetQuery{Before: &cursors[2], Limit: 5, Backward: true},
+			Page{Start: 0, End: 3, Cursors: cursors, HasNextPage: true}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			query, err := NewKeyset(test.args, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if reflect.DeepEqual(query, test.query) == false {
+				t.Fatalf("query %+v, expected %+v", query, test.query)
+			}
+			if page := query.Page(cursors); reflect.DeepEqual(page, test.page) == false {
+				t.Errorf("page %+v, expected %+v", page, test.page)
+			}
+		})
+	}
+}
+
+func TestCursors(t *testing.T) {
+	offset, err := CursorOffset(OffsetCursor(42))
+	if err != nil || offset != 42 {
+		t.Errorf("offset %d, error %v, expected 42", offset, err)
+	}
+	for _, invalid := range []string{"bad", OffsetCursor(-1), *stringp("b2Zmc2V0Ong=")} {
+		if _, err := CursorOffset(invalid); err == nil {
+			t.Errorf("expected %q to be an invalid offset cursor", invalid)
+		}
+	}
+
+	type key struct {
+		ID   int
+		Name string
+	}
+	encoded, err := KeyCursor(key{ID: 1, Name: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded key
+	if err := CursorKey(encoded, &decoded); err != nil || decoded != (key{ID: 1, Name: "a"}) {
+		t.Errorf("key %+v, error %v", decoded, err)
+	}
+
+	query := KeysetQuery{After: stringp("!")}
+	if _, err := query.AfterKey(&decoded); err == nil || err.Error() != `argument after: "!" is not a valid cursor` {
+		t.Errorf("unexpected error %v", err)
+	}
+	if ok, err := (KeysetQuery{}).BeforeKey(&decoded); ok == true || err != nil {
+		t.Errorf("expected no before key, got %v, %v", ok, err)
+	}
+}
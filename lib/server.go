@@ -0,0 +1,76 @@
+package lib
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Defaults of the Server timeouts
+const (
+	DefaultReadHeaderTimeout = 10 * time.Second
+	DefaultReadTimeout       = 30 * time.Second
+	DefaultWriteTimeout      = 30 * time.Second
+	DefaultIdleTimeout       = 120 * time.Second
+	DefaultShutdownTimeout   = 10 * time.Second
+)
+
+// Server is an http server with timeouts that shuts down gracefully, the
+// timeouts that are not set use the defaults above
+type Server struct {
+	Addr    string
+	Handler http.Handler
+
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+
+	// ShutdownTimeout is how long the server waits for active requests
+	// when the context is canceled
+	ShutdownTimeout time.Duration
+}
+
+func orDefault(value time.Duration, fallback time.Duration) time.Duration {
+	if value == 0 {
+		return fallback
+	}
+	return value
+}
+
+// ListenAndServe serves requests until the context is canceled, the server
+// then stops accepting connections and waits for the active requests to
+// finish. Websocket connections for subscriptions are not waited for
+func (server *Server) ListenAndServe(ctx context.Context) error {
+	httpServer := &http.Server{
+		Addr:              server.Addr,
+		Handler:           server.Handler,
+		ReadHeaderTimeout: orDefault(server.ReadHeaderTimeout, DefaultReadHeaderTimeout),
+		ReadTimeout:       orDefault(server.ReadTimeout, DefaultReadTimeout),
+		WriteTimeout:      orDefault(server.WriteTimeout, DefaultWriteTimeout),
+		IdleTimeout:       orDefault(server.IdleTimeout, DefaultIdleTimeout),
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(),
+		orDefault(server.ShutdownTimeout, DefaultShutdownTimeout))
+	defer cancel()
+
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errs; err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
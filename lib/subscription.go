@@ -0,0 +1,242 @@
+package lib
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/graphql-go/graphql"
+)
+
+// SubscriptionParams is the payload of a subscription request
+type SubscriptionParams struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+}
+
+// SubscribeFunc starts a subscription, a result is sent on the returned
+// channel for every event until the context is canceled
+type SubscribeFunc func(context.Context, SubscriptionParams) chan *graphql.Result
+
+// Websocket subprotocols supported by the SubscriptionHandler
+const (
+	// The protocol implemented by the graphql-ws library
+	GraphqlTransportWS = "graphql-transport-ws"
+	// The legacy protocol implemented by subscriptions-transport-ws
+	GraphqlWS = "graphql-ws"
+)
+
+type operationMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// Message types that differ between the two protocols
+type protocolMessages struct {
+	Subscribe string
+	Next      string
+	Stop      string
+}
+
+var messageTypes = map[string]protocolMessages{
+	GraphqlTransportWS: {
+		Subscribe: "subscribe",
+		Next:      "next",
+		Stop:      "complete",
+	},
+	GraphqlWS: {
+		Subscribe: "start",
+		Next:      "data",
+		Stop:      "stop",
+	},
+}
+
+// newUpgrader returns the websocket upgrader of a subscription endpoint. It
+// accepts handshakes from the same origin, and from the allowed origins in the
+// same way as the CORS headers of NewHandler do
+func newUpgrader(allowed []string) *websocket.Upgrader {
+	upgrader := &websocket.Upgrader{
+		Subprotocols: []string{GraphqlTransportWS, GraphqlWS},
+	}
+	if len(allowed) > 0 {
+		upgrader.CheckOrigin = func(r *http.Request) bool {
+			origin := r.Header.Get("Origin")
+			return origin == "" || sameOrigin(r, origin) || originAllowed(allowed, origin)
+		}
+	}
+	return upgrader
+}
+
+func sameOrigin(r *http.Request, origin string) bool {
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// SubscriptionHandler serves subscriptions over websockets, the client can
+// use either the graphql-transport-ws or the legacy graphql-ws protocol. Only
+// handshakes from the same origin are accepted, use NewHandler to accept other
+// origins as well
+func SubscriptionHandler(subscribe SubscribeFunc) http.Handler {
+	return subscriptionHandler(subscribe, newUpgrader(nil))
+}
+
+func subscriptionHandler(subscribe SubscribeFunc, upgrader *websocket.Upgrader) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		protocol := conn.Subprotocol()
+		if protocol == "" {
+			protocol = GraphqlWS
+		}
+
+		session := &subscriptionSession{
+			conn:          conn,
+			protocol:      protocol,
+			messages:      messageTypes[protocol],
+			subscribe:     subscribe,
+			subscriptions: make(map[string]context.CancelFunc),
+		}
+		session.serve(r.Context())
+	})
+}
+
+// closeSubscriberExists is the close code of graphql-transport-ws for a
+// subscribe message with the id of an active subscription
+const closeSubscriberExists = 4409
+
+type subscriptionSession struct {
+	conn      *websocket.Conn
+	protocol  string
+	messages  protocolMessages
+	subscribe SubscribeFunc
+
+	// Guards writes to the connection and the subscriptions map
+	lock          sync.Mutex
+	subscriptions map[string]context.CancelFunc
+}
+
+func (session *subscriptionSession) serve(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer session.conn.Close()
+
+	for {
+		var msg operationMessage
+		if err := session.conn.ReadJSON(&msg); err != nil {
+			return
+		}
+
+		switch msg.Type {
+		case "connection_init":
+			session.send(operationMessage{Type: "connection_ack"})
+		case "ping":
+			session.send(operationMessage{Type: "pong"})
+		case "connection_terminate":
+			return
+		case session.messages.Subscribe:
+			var params SubscriptionParams
+			if err := json.Unmarshal(msg.Payload, &params); err != nil {
+				session.sendError(msg.ID, err)
+				continue
+			}
+			session.start(ctx, msg.ID, params)
+		case session.messages.Stop:
+			session.stop(msg.ID)
+		}
+	}
+}
+
+func (session *subscriptionSession) start(ctx context.Context, id string, params SubscriptionParams) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	session.lock.Lock()
+	if _, exists := session.subscriptions[id]; exists {
+		session.lock.Unlock()
+		cancel()
+		// graphql-transport-ws closes the connection, the legacy protocol
+		// ignores the message
+		if session.protocol == GraphqlTransportWS {
+			session.close(closeSubscriberExists, "Subscriber for "+id+" already exists")
+		}
+		return
+	}
+	session.subscriptions[id] = cancel
+	session.lock.Unlock()
+
+	results := session.subscribe(ctx, params)
+
+	go func() {
+		for result := range results {
+			payload, err := json.Marshal(result)
+			if err != nil {
+				session.sendError(id, err)
+				session.stop(id)
+				// Drain the results until the subscription is closed
+				for range results {
+				}
+				return
+			}
+			session.send(operationMessage{
+				ID:      id,
+				Type:    session.messages.Next,
+				Payload: payload,
+			})
+		}
+
+		// Only notify the client if it didn't stop the subscription itself
+		if session.stop(id) == true {
+			session.send(operationMessage{ID: id, Type: "complete"})
+		}
+	}()
+}
+
+// stop cancels the subscription and reports whether it was still active
+func (session *subscriptionSession) stop(id string) bool {
+	session.lock.Lock()
+	defer session.lock.Unlock()
+
+	cancel, ok := session.subscriptions[id]
+	if ok == true {
+		cancel()
+		delete(session.subscriptions, id)
+	}
+	return ok
+}
+
+// sendError sends an error message, the payload is a list of errors for
+// graphql-transport-ws and a single error for the legacy protocol
+func (session *subscriptionSession) sendError(id string, err error) {
+	var payload []byte
+	if session.protocol == GraphqlWS {
+		payload, _ = json.Marshal(map[string]string{"message": err.Error()})
+	} else {
+		payload, _ = json.Marshal([]map[string]string{
+			{"message": err.Error()},
+		})
+	}
+	session.send(operationMessage{ID: id, Type: "error", Payload: payload})
+}
+
+// close closes the connection with the close code and reason, which ends
+// the session and its subscriptions
+func (session *subscriptionSession) close(code int, reason string) {
+	session.conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(code, reason), time.Now().Add(time.Second))
+	session.conn.Close()
+}
+
+func (session *subscriptionSession) send(msg operationMessage) {
+	session.lock.Lock()
+	defer session.lock.Unlock()
+	session.conn.WriteJSON(msg)
+}
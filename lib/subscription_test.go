@@ -0,0 +1,296 @@
+package lib
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/graphql-go/graphql"
+)
+
+// countSchema has a count subscription that sends the numbers up to its
+// argument and ends
+func countSchema(t *testing.T) graphql.Schema {
+	t.Helper()
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"hello": &graphql.Field{Type: graphql.String},
+			},
+		}),
+		Subscription: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Subscription",
+			Fields: graphql.Fields{
+				"count": &graphql.Field{
+					Type: graphql.NewNonNull(graphql.Int),
+					Args: graphql.FieldConfigArgument{
+						"to": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+					},
+					Subscribe: func(p graphql.ResolveParams) (interface{}, error) {
+						events := make(chan interface{})
+						go func() {
+							defer close(events)
+							for i := 1; i <= p.Args["to"].(int); i++ {
+								select {
+								case events <- i:
+								case <-p.Context.Done():
+									return
+								}
+							}
+						}()
+						return events, nil
+					},
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return p.Source, nil
+					},
+				},
+			},
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return schema
+}
+
+func subscribeTo(schema graphql.Schema) SubscribeFunc {
+	return func(ctx context.Context, params SubscriptionParams) chan *graphql.Result {
+		return graphql.Subscribe(graphql.Params{
+			Schema:         schema,
+			RequestString:  params.Query,
+			VariableValues: params.Variables,
+			OperationName:  params.OperationName,
+			Context:        ctx,
+		})
+	}
+}
+
+// dial connects to the subscription handler with the protocol and
+// acknowledges the connection
+func dial(t *testing.T, handler *httptest.Server, protocol string) *websocket.Conn {
+	t.Helper()
+	dialer := websocket.Dialer{Subprotocols: []string{protocol}}
+	url := "ws" + strings.TrimPrefix(handler.URL, "http")
+	conn, _, err := dialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conn.Subprotocol() != protocol {
+		t.Fatalf("subprotocol %q, expected %q", conn.Subprotocol(), protocol)
+	}
+
+	write(t, conn, operationMessage{Type: "connection_init"})
+	if msg := read(t, conn); msg.Type != "connection_ack" {
+		t.Fatalf("message %s, expected connection_ack", msg.Type)
+	}
+	return conn
+}
+
+func write(t *testing.T, conn *websocket.Conn, msg operationMessage) {
+	t.Helper()
+	if err := conn.WriteJSON(msg); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func read(t *testing.T, conn *websocket.Conn) operationMessage {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var msg operationMessage
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+func payload(t *testing.T, query string) json.RawMessage {
+	t.Helper()
+	encoded, err := json.Marshal(SubscriptionParams{Query: query})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return encoded
+}
+
+func TestSubscribe(t *testing.T) {
+	results := subscribeTo(countSchema(t))(context.Background(), SubscriptionParams{
+		Query: "subscription { count(to: 3) }",
+	})
+
+	var counts []string
+	for result := range results {
+		encoded, _ := json.Marshal(result)
+		counts = append(counts, string(encoded))
+	}
+	expected := []string{`{"data":{"count":1}}`, `{"data":{"count":2}}`, `{"data":{"count":3}}`}
+	if strings.Join(counts, " ") != strings.Join(expected, " ") {
+		t.Errorf("results %v, expected %v", counts, expected)
+	}
+}
+
+func TestSubscriptionHandler(t *testing.T) {
+	for protocol, messages := range messageTypes {
+		t.Run(protocol, func(t *testing.T) {
+			server := httptest.NewServer(SubscriptionHandler(subscribeTo(countSchema(t))))
+			defer server.Close()
+			conn := dial(t, server, protocol)
+			defer conn.Close()
+
+			write(t, conn, operationMessage{
+				ID:      "1",
+				Type:    messages.Subscribe,
+				Payload: payload(t, "subscription { count(to: 2) }"),
+			})
+
+			expected := []operationMessage{
+				{ID: "1", Type: messages.Next, Payload: json.RawMessage(`{"data":{"count":1}}`)},
+				{ID: "1", Type: messages.Next, Payload: json.RawMessage(`{"data":{"count":2}}`)},
+				{ID: "1", Type: "complete"},
+			}
+			for _, want := range expected {
+				msg := read(t, conn)
+				if msg.ID != want.ID || msg.Type != want.Type || string(msg.Payload) != string(want.Payload) {
+					t.Errorf("message %s %s %s, expected %s %s %s",
+						msg.ID, msg.Type, msg.Payload, want.ID, want.Type, want.Payload)
+				}
+			}
+		})
+	}
+}
+
+func TestSubscriptionHandlerStop(t *testing.T) {
+	for protocol, messages := range messageTypes {
+		t.Run(protocol, func(t *testing.T) {
+			stopped := make(chan struct{})
+			subscribe := func(ctx context.Context, params SubscriptionParams) chan *graphql.Result {
+				results := make(chan *graphql.Result)
+				go func() {
+					<-ctx.Done()
+					close(results)
+					close(stopped)
+				}()
+				return results
+			}
+
+			server := httptest.NewServer(SubscriptionHandler(subscribe))
+			defer server.Close()
+			conn := dial(t, server, protocol)
+			defer conn.Close()
+
+			write(t, conn, operationMessage{
+				ID:      "1",
+				Type:    messages.Subscribe,
+				Payload: payload(t, "subscription { count(to: 1) }"),
+			})
+			write(t, conn, operationMessage{ID: "1", Type: messages.Stop})
+
+			select {
+			case <-stopped:
+			case <-time.After(5 * time.Second):
+				t.Fatal("the subscription wasn't canceled")
+			}
+
+			// A subscription stopped by the client isn't completed by the
+			// server, so the next message is the answer to the ping
+			write(t, conn, operationMessage{Type: "ping"})
+			if msg := read(t, conn); msg.Type != "pong" {
+				t.Errorf("message %s %s, expected pong", msg.ID, msg.Type)
+			}
+		})
+	}
+}
+
+func TestSubscriptionHandlerInvalidPayload(t *testing.T) {
+	tests := []struct {
+		protocol string
+		payload  string
+	}{
+		{GraphqlTransportWS, `[{"message":"json: cannot unmarshal string into Go value of type lib.SubscriptionParams"}]`},
+		{GraphqlWS, `{"message":"json: cannot unmarshal string into Go value of type lib.SubscriptionParams"}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.protocol, func(t *testing.T) {
+			server := httptest.NewServer(SubscriptionHandler(subscribeTo(countSchema(t))))
+			defer server.Close()
+			conn := dial(t, server, test.protocol)
+			defer conn.Close()
+
+			write(t, conn, operationMessage{
+				ID:      "1",
+				Type:    messageTypes[test.protocol].Subscribe,
+				Payload: json.RawMessage(`"query"`),
+			})
+			msg := read(t, conn)
+			if msg.ID != "1" || msg.Type != "error" || string(msg.Payload) != test.payload {
+				t.Errorf("message %s %s %s, expected an error with the payload %s",
+					msg.ID, msg.Type, msg.Payload, test.payload)
+			}
+		})
+	}
+}
+
+func TestSubscriptionHandlerDuplicateID(t *testing.T) {
+	// The subscription never ends so the id stays in use
+	subscribe := func(ctx context.Context, params SubscriptionParams) chan *graphql.Result {
+		results := make(chan *graphql.Result)
+		go func() {
+			<-ctx.Done()
+			close(results)
+		}()
+		return results
+	}
+
+	t.Run(GraphqlTransportWS, func(t *testing.T) {
+		server := httptest.NewServer(SubscriptionHandler(subscribe))
+		defer server.Close()
+		conn := dial(t, server, GraphqlTransportWS)
+		defer conn.Close()
+
+		subscription := operationMessage{
+			ID:      "1",
+			Type:    "subscribe",
+			Payload: payload(t, "subscription { count(to: 1) }"),
+		}
+		write(t, conn, subscription)
+		write(t, conn, subscription)
+
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		var msg operationMessage
+		err := conn.ReadJSON(&msg)
+		closeErr, ok := err.(*websocket.CloseError)
+		if ok == false {
+			t.Fatalf("read %+v, %v, expected the connection to be closed", msg, err)
+		}
+		if closeErr.Code != 4409 || closeErr.Text != "Subscriber for 1 already exists" {
+			t.Errorf("closed with %d %q, expected 4409 \"Subscriber for 1 already exists\"",
+				closeErr.Code, closeErr.Text)
+		}
+	})
+
+	t.Run(GraphqlWS, func(t *testing.T) {
+		server := httptest.NewServer(SubscriptionHandler(subscribe))
+		defer server.Close()
+		conn := dial(t, server, GraphqlWS)
+		defer conn.Close()
+
+		subscription := operationMessage{
+			ID:      "1",
+			Type:    "start",
+			Payload: payload(t, "subscription { count(to: 1) }"),
+		}
+		write(t, conn, subscription)
+		write(t, conn, subscription)
+
+		// The repeated id is ignored and the connection stays open
+		write(t, conn, operationMessage{Type: "ping"})
+		if msg := read(t, conn); msg.Type != "pong" {
+			t.Errorf("message %s %s, expected pong", msg.ID, msg.Type)
+		}
+	})
+}
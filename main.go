@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/granateio/granate/generator"
@@ -9,13 +10,15 @@ import (
 
 // Flags Code generator options
 type Flags struct {
-	Config string `short:"c" long:"config" description:"Path to <config>.yaml file"`
-	Help   bool   `short:"h" long:"help" description:"Show available options"`
+	Config    string `short:"c" long:"config" description:"Path to <config>.yaml file"`
+	Templates string `short:"t" long:"templates" description:"Directory with templates overriding the built-in language templates"`
+	Help      bool   `short:"h" long:"help" description:"Show available options"`
 }
 
 func check(e error) {
 	if e != nil {
-		panic(e)
+		fmt.Fprintln(os.Stderr, e)
+		os.Exit(1)
 	}
 }
 
@@ -25,7 +28,7 @@ func main() {
 		Help:   false,
 	}
 
-	parser := flags.NewParser(&params, flags.Default^flags.HelpFlag)
+	parser := flags.NewParser(&params, flags.Default^flags.HelpFlag^flags.PrintErrors)
 	_, err := parser.Parse()
 	check(err)
 
@@ -34,8 +37,17 @@ func main() {
 		return
 	}
 
-	file := params.Config
+	conf, err := generator.LoadConfig(params.Config)
+	if err != nil {
+		check(&generator.ConfigError{Path: params.Config, Err: err})
+	}
+
+	if params.Templates != "" {
+		conf.TemplateDir = params.Templates
+	}
+
+	gen, err := generator.NewFromConfig(conf)
+	check(err)
 
-	gen, _ := generator.New(file)
-	gen.Generate()
+	check(gen.Generate())
 }